@@ -1,44 +1,177 @@
 package ngore
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"log/slog"
 	"net/http"
 	"net/http/cookiejar"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gar-r/ngore/activity"
 	"github.com/gar-r/ngore/details"
+	"github.com/gar-r/ngore/download"
 	"github.com/gar-r/ngore/internal"
 	"github.com/gar-r/ngore/login"
+	"github.com/gar-r/ngore/profile"
+	"github.com/gar-r/ngore/ratelimit"
 	"github.com/gar-r/ngore/recommended"
+	"github.com/gar-r/ngore/retry"
+	"github.com/gar-r/ngore/rss"
 	"github.com/gar-r/ngore/search"
+	"github.com/gar-r/ngore/session"
+	"github.com/gar-r/ngore/torrent"
 	"golang.org/x/net/html"
 )
 
 type Api interface {
 	Login(auth login.Auth) error
+	LoginContext(ctx context.Context, auth login.Auth) error
 	Search(params *search.Params) (*search.Result, error)
+	SearchContext(ctx context.Context, params *search.Params) (*search.Result, error)
+	SearchAll(params *search.Params, limit *search.Limit) iter.Seq2[*search.Torrent, error]
+	SearchAllContext(ctx context.Context, params *search.Params, limit *search.Limit) iter.Seq2[*search.Torrent, error]
+	SearchConcurrent(params *search.Params, limit *search.Limit, workers int) ([]*search.Torrent, error)
+	SearchConcurrentContext(ctx context.Context, params *search.Params, limit *search.Limit, workers int) ([]*search.Torrent, error)
 	Activity() (*activity.Info, error)
+	ActivityContext(ctx context.Context) (*activity.Info, error)
 	Recommendations() (*recommended.Recommendations, error)
+	RecommendationsContext(ctx context.Context) (*recommended.Recommendations, error)
 	Details(id string) (*details.Details, error)
+	DetailsContext(ctx context.Context, id string) (*details.Details, error)
+	Files(id string) ([]*details.File, error)
+	FilesContext(ctx context.Context, id string) ([]*details.File, error)
+	Nfo(id string) (string, error)
+	NfoContext(ctx context.Context, id string) (string, error)
+	Comments(id string, page int) (*details.Comments, error)
+	CommentsContext(ctx context.Context, id string, page int) (*details.Comments, error)
 	Download(id string) ([]byte, error)
+	DownloadContext(ctx context.Context, id string) ([]byte, error)
+	DownloadTo(id string, w io.Writer) (*download.Info, error)
+	DownloadToContext(ctx context.Context, id string, w io.Writer) (*download.Info, error)
+	DownloadFile(id string, path string) (*download.Info, error)
+	DownloadFileContext(ctx context.Context, id string, path string) (*download.Info, error)
+	Magnet(id string, stripPasskey bool) (string, error)
+	MagnetContext(ctx context.Context, id string, stripPasskey bool) (string, error)
+	Rss(categories ...search.Category) (*rss.Feed, error)
+	RssContext(ctx context.Context, categories ...search.Category) (*rss.Feed, error)
+	Bookmarks(page int) (*search.Result, error)
+	BookmarksContext(ctx context.Context, page int) (*search.Result, error)
+	AddBookmark(id string) error
+	AddBookmarkContext(ctx context.Context, id string) error
+	RemoveBookmark(id string) error
+	RemoveBookmarkContext(ctx context.Context, id string) error
+	Profile() (*profile.Profile, error)
+	ProfileContext(ctx context.Context) (*profile.Profile, error)
+	UserProfile(id string) (*profile.Profile, error)
+	UserProfileContext(ctx context.Context, id string) (*profile.Profile, error)
+	AutoLogin(auth login.Auth) error
+	AutoLoginContext(ctx context.Context, auth login.Auth) error
+	Session() (*session.Session, error)
+	SetRateLimit(limiter *ratelimit.Limiter)
+	SetDownloadRateLimit(limiter *ratelimit.Limiter)
+	SetRetryPolicy(policy *retry.Policy)
 }
 
+const maxErrorPageSize = 1 << 20
+
+// torrentFileMode is the permission of the saved torrent files
+const torrentFileMode = 0o644
+
 type api struct {
-	baseUrl string
-	key     string
-	client  *http.Client
+	baseUrl  string
+	client   *http.Client
+	header   http.Header
+	timeout  time.Duration
+	timeouts map[string]time.Duration
+	logger   *slog.Logger
+
+	mu   sync.RWMutex
+	key  string
+	auth login.Auth
+
+	// limiter paces every request, except downloads when downloadLimiter is set
+	limiter         *ratelimit.Limiter
+	downloadLimiter *ratelimit.Limiter
+	retryPolicy     *retry.Policy
+
+	// loginMu serializes re-logins, gen counts the attempts, so that concurrent
+	// callers can tell if a re-login already happened, and share its result in loginErr
+	loginMu  sync.Mutex
+	gen      atomic.Uint64
+	loginErr error
+
+	// restored is set while a session restored from a snapshot
+	// has not been confirmed by the server yet
+	restored atomic.Bool
+	expired  atomic.Bool
+
+	// keyOnly is set while the api has a key, but no session
+	keyOnly atomic.Bool
 }
 
 func New(client *http.Client, baseUrl string) Api {
-	initCookieJar(client)
-	disableRedirect(client)
-	return &api{
-		client:  client,
-		baseUrl: baseUrl,
+	a, _ := NewWithOptions(baseUrl, WithClient(client)) // cannot fail without a proxy
+	return a
+}
+
+func NewWithOptions(baseUrl string, opts ...Option) (Api, error) {
+	o := &options{
+		timeouts: make(map[string]time.Duration),
+		header:   make(http.Header),
+		logger:   slog.New(slog.DiscardHandler),
 	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	client, err := o.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	a := &api{
+		baseUrl:         baseUrl,
+		client:          client,
+		header:          o.header,
+		timeout:         o.timeout,
+		timeouts:        o.timeouts,
+		logger:          o.logger,
+		limiter:         o.limiter,
+		downloadLimiter: o.downloadLimiter,
+		retryPolicy:     o.retryPolicy,
+	}
+	if o.session != nil {
+		if err := a.restore(o.session); err != nil {
+			return nil, err
+		}
+	} else if o.key != "" {
+		a.setKey(o.key)
+		a.keyOnly.Store(true)
+	}
+	return a, nil
+}
+
+func Restore(client *http.Client, baseUrl string, s *session.Session) (Api, error) {
+	return NewWithOptions(baseUrl, WithClient(client), WithSession(s))
+}
+
+// FromKey creates an api, which can download torrents and read the rss feed
+// with the given key, without logging in.
+func FromKey(client *http.Client, baseUrl string, key string) (Api, error) {
+	if key == "" {
+		return nil, ErrApiKeyEmpty
+	}
+	return NewWithOptions(baseUrl, WithClient(client), WithKey(key))
 }
 
 func Default(baseUrl string) Api {
@@ -49,128 +182,943 @@ func Default(baseUrl string) Api {
 }
 
 func (a *api) Login(auth login.Auth) error {
-	if auth.User() == "" || auth.Pass() == "" {
-		return errors.New(internal.ErrLoginMissingCredentials)
-	}
-	res, err := a.client.PostForm(a.baseUrl+internal.UrlLogin, internal.AuthForm(auth))
+	return a.LoginContext(context.Background(), auth)
+}
+
+func (a *api) LoginContext(ctx context.Context, auth login.Auth) error {
+	ctx, cancel := a.withTimeout(ctx, internal.OpLogin)
+	defer cancel()
+	return a.login(ctx, auth)
+}
+
+func (a *api) AutoLogin(auth login.Auth) error {
+	return a.AutoLoginContext(context.Background(), auth)
+}
+
+func (a *api) AutoLoginContext(ctx context.Context, auth login.Auth) error {
+	ctx, cancel := a.withTimeout(ctx, internal.OpLogin)
+	defer cancel()
+	err := a.login(ctx, auth)
 	if err != nil {
 		return err
 	}
-	if internal.IsInvalidLogin(res) {
-		return errors.New(internal.ErrLoginInvalidCredentials)
-	}
-	if internal.IsSuccessfulLogin(res) {
-		return a.fetchKey()
-	}
-	return errors.New(internal.ErrLoginUnexpectedResponse)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.auth = auth
+	return nil
 }
 
 func (a *api) Search(params *search.Params) (*search.Result, error) {
-	res, err := a.client.PostForm(a.baseUrl+internal.UrlTorrents, internal.SearchForm(params))
+	return a.SearchContext(context.Background(), params)
+}
+
+func (a *api) SearchContext(ctx context.Context, params *search.Params) (*search.Result, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpSearch)
+	defer cancel()
+	url := a.baseUrl + internal.UrlTorrents
+	form := internal.SearchForm(params)
+	doc, err := a.fetchDocument(ctx, internal.OpSearch, func() (*http.Request, error) {
+		return newPostForm(ctx, url, form)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if internal.IsLoginRequired(res) {
-		return nil, errors.New(internal.ErrUserNotLoggedIn)
+	return a.setDownloadUrls(search.ParseResponse(doc)), nil
+}
+
+func (a *api) SearchAll(params *search.Params, limit *search.Limit) iter.Seq2[*search.Torrent, error] {
+	return a.SearchAllContext(context.Background(), params, limit)
+}
+
+// SearchAllContext iterates over the results of every page, starting from the page in
+// the params. Pages are fetched as the iteration advances, and the params are not modified.
+// The iteration stops after yielding an error.
+func (a *api) SearchAllContext(ctx context.Context, params *search.Params, limit *search.Limit) iter.Seq2[*search.Torrent, error] {
+	if limit == nil {
+		limit = &search.Limit{}
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(internal.ErrSearchUnexpectedResponseCode, res.StatusCode)
+	return func(yield func(*search.Torrent, error) bool) {
+		p := *params
+		p.Page = max(p.Page, 1)
+		results := 0
+		for pages := 1; ; pages++ {
+			res, err := a.SearchContext(ctx, &p)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, t := range res.Torrents {
+				if limit.Reached(results, 0) {
+					return
+				}
+				results++
+				if !yield(t, nil) {
+					return
+				}
+			}
+			if len(res.Torrents) == 0 || !res.Page.HasMore() || limit.Reached(results, pages) {
+				return
+			}
+			p.Page = res.Page.Next
+		}
 	}
-	doc, err := html.Parse(res.Body)
+}
+
+func (a *api) SearchConcurrent(params *search.Params, limit *search.Limit, workers int) ([]*search.Torrent, error) {
+	return a.SearchConcurrentContext(context.Background(), params, limit, workers)
+}
+
+// SearchConcurrentContext fetches the page in the params, then the rest of the pages up to the
+// last one in the pager, or as many as the limit needs, with at most the given number of
+// requests in flight. The torrents are
+// returned in the order of the pages, without duplicates. The params are not modified.
+func (a *api) SearchConcurrentContext(ctx context.Context, params *search.Params, limit *search.Limit, workers int) ([]*search.Torrent, error) {
+	if limit == nil {
+		limit = &search.Limit{}
+	}
+	p := *params
+	p.Page = max(p.Page, 1)
+	first, err := a.SearchContext(ctx, &p)
 	if err != nil {
 		return nil, err
 	}
-	return search.ParseResponse(doc), nil
+	count := max(first.Page.Last-p.Page+1, 1)
+	if len(first.Torrents) == 0 {
+		count = 1
+	}
+	if limit.MaxPages > 0 {
+		count = min(count, limit.MaxPages)
+	}
+	if limit.MaxResults > 0 && len(first.Torrents) > 0 {
+		// every page but the last one is as long as the first
+		count = min(count, (limit.MaxResults+len(first.Torrents)-1)/len(first.Torrents))
+	}
+	pages := make([][]*search.Torrent, count)
+	pages[0] = first.Torrents
+	if err := a.fetchPages(ctx, &p, pages, max(workers, 1)); err != nil {
+		return nil, err
+	}
+	torrents := search.Merge(pages...)
+	if limit.MaxResults > 0 && len(torrents) > limit.MaxResults {
+		torrents = torrents[:limit.MaxResults]
+	}
+	return torrents, nil
+}
+
+// fetchPages fills the pages following the first one, which is already present.
+// The first error cancels the requests still running.
+func (a *api) fetchPages(ctx context.Context, params *search.Params, pages [][]*search.Torrent, workers int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, workers)
+	for i := 1; i < len(pages) && ctx.Err() == nil; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+		p := *params
+		p.Page += i
+		wg.Go(func() {
+			defer func() { <-sem }()
+			res, err := a.SearchContext(ctx, &p)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			pages[i] = res.Torrents
+		})
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
 }
 
 func (a *api) Activity() (*activity.Info, error) {
-	res, err := a.client.Get(a.baseUrl + internal.UrlActivity)
+	return a.ActivityContext(context.Background())
+}
+
+func (a *api) ActivityContext(ctx context.Context) (*activity.Info, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpActivity)
+	defer cancel()
+	doc, err := a.getDocument(ctx, internal.OpActivity, a.baseUrl+internal.UrlActivity)
 	if err != nil {
 		return nil, err
 	}
-	if internal.IsLoginRequired(res) {
-		return nil, errors.New(internal.ErrUserNotLoggedIn)
+	return activity.ParseResponse(doc), nil
+}
+
+func (a *api) Recommendations() (*recommended.Recommendations, error) {
+	return a.RecommendationsContext(context.Background())
+}
+
+func (a *api) RecommendationsContext(ctx context.Context) (*recommended.Recommendations, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(internal.ErrActivityUnexpectedResponseCode, res.StatusCode)
+	ctx, cancel := a.withTimeout(ctx, internal.OpRecommendations)
+	defer cancel()
+	doc, err := a.getDocument(ctx, internal.OpRecommendations, a.baseUrl+internal.UrlRecommended)
+	if err != nil {
+		return nil, err
 	}
-	doc, err := html.Parse(res.Body)
+	return recommended.ParseRecommendations(doc), nil
+}
+
+func (a *api) Download(id string) ([]byte, error) {
+	return a.DownloadContext(context.Background(), id)
+}
+
+func (a *api) DownloadContext(ctx context.Context, id string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	_, err := a.DownloadToContext(ctx, id, buf)
 	if err != nil {
 		return nil, err
 	}
-	return activity.ParseResponse(doc), nil
+	if err := validateTorrent(id, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
-func (a *api) Recommendations() (*recommended.Recommendations, error) {
-	res, err := a.client.Get(a.baseUrl + internal.UrlRecommended)
+func (a *api) DownloadTo(id string, w io.Writer) (*download.Info, error) {
+	return a.DownloadToContext(context.Background(), id, w)
+}
+
+func (a *api) DownloadToContext(ctx context.Context, id string, w io.Writer) (*download.Info, error) {
+	if a.getKey() == "" {
+		return nil, ErrApiKeyEmpty
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpDownload)
+	defer cancel()
+	// the key may change during a re-login, so the url is evaluated for every attempt
+	res, err := a.fetch(ctx, internal.OpDownload, func() (*http.Request, error) {
+		query := fmt.Sprintf("?action=download&id=%s&key=%s", id, a.getKey())
+		return newGet(ctx, a.baseUrl+internal.UrlTorrents+query)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if internal.IsLoginRequired(res) {
-		return nil, errors.New(internal.ErrUserNotLoggedIn)
+	defer res.Body.Close()
+	info := download.ParseHeader(res)
+	// only the beginning can be checked before streaming, a complete validation
+	// is done by Download and DownloadFile, which have the whole torrent at hand
+	body := bufio.NewReader(res.Body)
+	prefix, _ := body.Peek(1)
+	if !internal.LooksLikeTorrent(prefix) {
+		return nil, parseDownloadError(id, body)
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(internal.ErrActivityUnexpectedResponseCode, res.StatusCode)
+	if _, err := io.Copy(w, body); err != nil {
+		return nil, err
 	}
-	doc, err := html.Parse(res.Body)
+	return info, nil
+}
+
+func (a *api) DownloadFile(id string, path string) (*download.Info, error) {
+	return a.DownloadFileContext(context.Background(), id, path)
+}
+
+// DownloadFileContext writes the torrent to a temporary file first, and renames
+// it to its final path only after the download succeeded. When the path is an
+// existing directory, the file name suggested by the server is used.
+func (a *api) DownloadFileContext(ctx context.Context, id string, path string) (*download.Info, error) {
+	dir, name := filepath.Split(path)
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		dir, name = path, ""
+	}
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, ".ngore-*.tmp")
 	if err != nil {
 		return nil, err
 	}
-	return recommended.ParseRecommendations(doc), nil
+	info, err := a.DownloadToContext(ctx, id, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = validateTorrentFile(id, tmp.Name())
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	if name == "" {
+		name = info.FileName
+	}
+	if name == "" {
+		name = id + ".torrent"
+	}
+	info.Path = filepath.Join(dir, name)
+	// temp files are private, but torrent clients watching the directory
+	// may run as a different user
+	if err := os.Chmod(tmp.Name(), torrentFileMode); err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), info.Path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	return info, nil
 }
 
-func (a *api) Download(id string) ([]byte, error) {
-	if a.key == "" {
-		return nil, errors.New(internal.ErrApiKeyEmpty)
+func (a *api) Magnet(id string, stripPasskey bool) (string, error) {
+	return a.MagnetContext(context.Background(), id, stripPasskey)
+}
+
+// MagnetContext downloads the torrent, and builds a magnet link from its metainfo.
+func (a *api) MagnetContext(ctx context.Context, id string, stripPasskey bool) (string, error) {
+	data, err := a.DownloadContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	m, err := torrent.Parse(data)
+	if err != nil {
+		return "", err
+	}
+	return m.Magnet(stripPasskey), nil
+}
+
+func (a *api) Rss(categories ...search.Category) (*rss.Feed, error) {
+	return a.RssContext(context.Background(), categories...)
+}
+
+func (a *api) RssContext(ctx context.Context, categories ...search.Category) (*rss.Feed, error) {
+	if a.getKey() == "" {
+		return nil, ErrApiKeyEmpty
 	}
-	query := fmt.Sprintf("?action=download&id=%s&key=%s", id, a.key)
-	url := a.baseUrl + internal.UrlTorrents + query
-	res, err := a.client.Get(url)
+	ctx, cancel := a.withTimeout(ctx, internal.OpRss)
+	defer cancel()
+	res, err := a.fetch(ctx, internal.OpRss, func() (*http.Request, error) {
+		query := internal.RssQuery(a.getKey(), categories).Encode()
+		return newGet(ctx, a.baseUrl+internal.UrlRss+"?"+query)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if internal.IsLoginRequired(res) {
-		return nil, errors.New(internal.ErrUserNotLoggedIn)
+	defer res.Body.Close()
+	return rss.ParseFeed(res.Body)
+}
+
+func (a *api) Bookmarks(page int) (*search.Result, error) {
+	return a.BookmarksContext(context.Background(), page)
+}
+
+// BookmarksContext lists the bookmarked torrents on the given page, starting from 1.
+func (a *api) BookmarksContext(ctx context.Context, page int) (*search.Result, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(internal.ErrDownloadUnexpectedResponseCode, res.StatusCode)
+	ctx, cancel := a.withTimeout(ctx, internal.OpBookmarks)
+	defer cancel()
+	query := fmt.Sprintf("?oldal=%d", max(page, 1))
+	doc, err := a.getDocument(ctx, internal.OpBookmarks, a.baseUrl+internal.UrlBookmarks+query)
+	if err != nil {
+		return nil, err
 	}
-	return io.ReadAll(res.Body)
+	return a.setDownloadUrls(search.ParseResponse(doc)), nil
+}
+
+func (a *api) AddBookmark(id string) error {
+	return a.AddBookmarkContext(context.Background(), id)
+}
+
+func (a *api) AddBookmarkContext(ctx context.Context, id string) error {
+	return a.updateBookmark(ctx, "add", id)
+}
+
+func (a *api) RemoveBookmark(id string) error {
+	return a.RemoveBookmarkContext(context.Background(), id)
+}
+
+func (a *api) RemoveBookmarkContext(ctx context.Context, id string) error {
+	return a.updateBookmark(ctx, "del", id)
+}
+
+func (a *api) updateBookmark(ctx context.Context, action string, id string) error {
+	if a.keyOnly.Load() {
+		return ErrKeyOnly
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpBookmark)
+	defer cancel()
+	query := fmt.Sprintf("?action=%s&id=%s", action, id)
+	res, err := a.fetch(ctx, internal.OpBookmark, func() (*http.Request, error) {
+		return newGet(ctx, a.baseUrl+internal.UrlBookmarks+query)
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorPageSize))
+	if err != nil {
+		return err
+	}
+	if internal.IsPage(body) {
+		return ErrBookmarkFailed
+	}
+	return nil
+}
+
+func (a *api) Profile() (*profile.Profile, error) {
+	return a.ProfileContext(context.Background())
+}
+
+// ProfileContext fetches the profile of the logged in user.
+func (a *api) ProfileContext(ctx context.Context) (*profile.Profile, error) {
+	return a.fetchProfile(ctx, a.baseUrl+internal.UrlProfile)
+}
+
+func (a *api) UserProfile(id string) (*profile.Profile, error) {
+	return a.UserProfileContext(context.Background(), id)
+}
+
+// UserProfileContext fetches the profile of the user with the given id.
+func (a *api) UserProfileContext(ctx context.Context, id string) (*profile.Profile, error) {
+	return a.fetchProfile(ctx, a.baseUrl+internal.UrlProfile+"?id="+neturl.QueryEscape(id))
+}
+
+func (a *api) fetchProfile(ctx context.Context, url string) (*profile.Profile, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpProfile)
+	defer cancel()
+	doc, err := a.getDocument(ctx, internal.OpProfile, url)
+	if err != nil {
+		return nil, err
+	}
+	return profile.ParseResponse(doc), nil
 }
 
 func (a *api) Details(id string) (*details.Details, error) {
+	return a.DetailsContext(context.Background(), id)
+}
+
+func (a *api) DetailsContext(ctx context.Context, id string) (*details.Details, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpDetails)
+	defer cancel()
 	query := fmt.Sprintf("?action=details&id=%s", id)
-	url := a.baseUrl + internal.UrlTorrents + query
-	res, err := a.client.Get(url)
+	doc, err := a.getDocument(ctx, internal.OpDetails, a.baseUrl+internal.UrlTorrents+query)
 	if err != nil {
 		return nil, err
 	}
-	if internal.IsLoginRequired(res) {
-		return nil, errors.New(internal.ErrUserNotLoggedIn)
+	d := details.ParseDetails(doc)
+	// the other versions are loaded on demand by the details page, they are
+	// left empty when that fails, as the details themselves are already here
+	if ref, ok := details.ParseOtherVersionsRef(doc); ok {
+		query := fmt.Sprintf("?action=other_versions&id=%s&fid=%s", id, ref)
+		doc, err := a.getDocument(ctx, internal.OpDetails, a.baseUrl+internal.UrlAjax+query)
+		if err != nil {
+			a.logger.WarnContext(ctx, "loading other versions failed", "id", id, "error", err)
+		} else {
+			d.OtherVersions = details.ParseOtherVersions(doc)
+		}
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(internal.ErrDetailsUnexpectedResponseCode, res.StatusCode)
+	return d, nil
+}
+
+func (a *api) Files(id string) ([]*details.File, error) {
+	return a.FilesContext(context.Background(), id)
+}
+
+// FilesContext fetches the list of files in a torrent, which the
+// details page of the site loads on demand.
+func (a *api) FilesContext(ctx context.Context, id string) ([]*details.File, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
 	}
-	doc, err := html.Parse(res.Body)
+	ctx, cancel := a.withTimeout(ctx, internal.OpFiles)
+	defer cancel()
+	query := fmt.Sprintf("?action=files&id=%s", id)
+	doc, err := a.getDocument(ctx, internal.OpFiles, a.baseUrl+internal.UrlAjax+query)
 	if err != nil {
 		return nil, err
 	}
-	return details.ParseDetails(doc), nil
+	return details.ParseFiles(doc), nil
 }
 
-func (a *api) fetchKey() error {
-	res, err := a.client.Get(a.baseUrl + internal.UrlIndex)
+func (a *api) Nfo(id string) (string, error) {
+	return a.NfoContext(context.Background(), id)
+}
+
+// NfoContext fetches the nfo of a torrent, which the details page loads on demand.
+func (a *api) NfoContext(ctx context.Context, id string) (string, error) {
+	if a.keyOnly.Load() {
+		return "", ErrKeyOnly
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpNfo)
+	defer cancel()
+	query := fmt.Sprintf("?action=nfo&id=%s", id)
+	doc, err := a.getDocument(ctx, internal.OpNfo, a.baseUrl+internal.UrlAjax+query)
 	if err != nil {
-		return errors.New(internal.ErrLoginUnableToFetchIndex)
+		return "", err
 	}
-	doc, err := html.Parse(res.Body)
+	return details.ParseNfo(doc), nil
+}
+
+func (a *api) Comments(id string, page int) (*details.Comments, error) {
+	return a.CommentsContext(context.Background(), id, page)
+}
+
+// CommentsContext fetches the comments of a torrent on the given page, starting from 1.
+func (a *api) CommentsContext(ctx context.Context, id string, page int) (*details.Comments, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
+	}
+	ctx, cancel := a.withTimeout(ctx, internal.OpComments)
+	defer cancel()
+	page = max(page, 1)
+	query := fmt.Sprintf("?action=details&id=%s&oldal=%d", id, page)
+	doc, err := a.getDocument(ctx, internal.OpComments, a.baseUrl+internal.UrlTorrents+query)
+	if err != nil {
+		return nil, err
+	}
+	return details.ParseComments(doc, page), nil
+}
+
+func (a *api) restore(s *session.Session) error {
+	u, err := neturl.Parse(a.baseUrl)
+	if err != nil {
+		return ErrInvalidBaseUrl
+	}
+	cookies := make([]*http.Cookie, 0, len(s.Cookies))
+	for _, c := range s.Cookies {
+		cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	a.client.Jar.SetCookies(u, cookies)
+	a.setKey(s.Key)
+	a.restored.Store(true)
+	return nil
+}
+
+func (a *api) Session() (*session.Session, error) {
+	if a.keyOnly.Load() {
+		return nil, ErrKeyOnly
+	}
+	key := a.getKey()
+	if key == "" {
+		return nil, ErrUserNotLoggedIn
+	}
+	u, err := neturl.Parse(a.baseUrl)
+	if err != nil {
+		return nil, ErrInvalidBaseUrl
+	}
+	s := &session.Session{
+		Key:     key,
+		Cookies: make([]*session.Cookie, 0),
+	}
+	for _, c := range a.client.Jar.Cookies(u) {
+		s.Cookies = append(s.Cookies, &session.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return s, nil
+}
+
+func (a *api) SetRateLimit(limiter *ratelimit.Limiter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.limiter = limiter
+}
+
+func (a *api) SetDownloadRateLimit(limiter *ratelimit.Limiter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.downloadLimiter = limiter
+}
+
+func (a *api) SetRetryPolicy(policy *retry.Policy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.retryPolicy = policy
+}
+
+func (a *api) login(ctx context.Context, auth login.Auth) error {
+	if auth.User() == "" || auth.Pass() == "" {
+		return ErrMissingCredentials
+	}
+	res, err := a.postLogin(ctx, internal.AuthForm(auth))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		doc, err := html.Parse(res.Body)
+		if err != nil {
+			return err
+		}
+		if internal.IsTwoFactorChallenge(doc) {
+			return a.loginTwoFactor(ctx, auth)
+		}
+	}
+	return a.loginResult(ctx, res)
+}
+
+func (a *api) loginTwoFactor(ctx context.Context, auth login.Auth) error {
+	code, err := twoFactorCode(auth)
+	if err != nil {
+		return err
+	}
+	res, err := a.postLogin(ctx, internal.TwoFactorForm(auth, code))
 	if err != nil {
 		return err
 	}
-	a.key, err = internal.ExtractKey(doc)
+	defer res.Body.Close()
+	return a.loginResult(ctx, res)
+}
+
+func (a *api) loginResult(ctx context.Context, res *http.Response) error {
+	if internal.IsInvalidLogin(res) {
+		return ErrInvalidCredentials
+	}
+	if internal.IsSuccessfulLogin(res) {
+		a.restored.Store(false)
+		a.expired.Store(false)
+		a.keyOnly.Store(false)
+		return a.fetchKey(ctx)
+	}
+	return ErrUnexpectedLoginResponse
+}
+
+func (a *api) postLogin(ctx context.Context, form neturl.Values) (*http.Response, error) {
+	return a.do(ctx, internal.OpLogin, func() (*http.Request, error) {
+		return newPostForm(ctx, a.baseUrl+internal.UrlLogin, form)
+	})
+}
+
+func twoFactorCode(auth login.Auth) (string, error) {
+	tfa, ok := auth.(login.TwoFactorAuth)
+	if !ok || tfa.CodeProvider() == nil {
+		return "", ErrTwoFactorCodeRequired
+	}
+	code, err := tfa.CodeProvider().Code()
+	if err != nil {
+		return "", err
+	}
+	if code == "" {
+		return "", ErrTwoFactorCodeRequired
+	}
+	return code, nil
+}
+
+// relogin logs in again with the remembered credentials, unless another caller
+// has already tried since the given login generation, in which case its result
+// is returned. Invalid credentials, or a missing two-factor code turn auto login off,
+// as trying again can not succeed, and would only risk locking the account.
+func (a *api) relogin(ctx context.Context, gen uint64) error {
+	a.loginMu.Lock()
+	defer a.loginMu.Unlock()
+	if a.gen.Load() != gen {
+		return a.loginErr
+	}
+	a.mu.RLock()
+	auth := a.auth
+	a.mu.RUnlock()
+	if auth == nil {
+		return a.loginRequired()
+	}
+	err := a.login(ctx, auth)
+	if err != nil && ctx.Err() != nil {
+		// the caller gave up, the next one may try again
+		return err
+	}
+	if errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrTwoFactorCodeRequired) {
+		a.mu.Lock()
+		a.auth = nil
+		a.mu.Unlock()
+	}
+	a.loginErr = err
+	a.gen.Add(1)
 	return err
 }
 
+func (a *api) fetchKey(ctx context.Context) error {
+	res, err := a.do(ctx, internal.OpLogin, func() (*http.Request, error) {
+		return newGet(ctx, a.baseUrl+internal.UrlIndex)
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	doc, err := html.Parse(res.Body)
+	if err != nil {
+		return err
+	}
+	key, err := internal.ExtractKey(doc)
+	if err != nil {
+		return err
+	}
+	a.setKey(key)
+	return nil
+}
+
+func (a *api) withTimeout(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	timeout, ok := a.timeouts[op]
+	if !ok {
+		timeout = a.timeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// setDownloadUrls makes the download links of the results absolute,
+// and builds the links missing from the rows from the api key.
+func (a *api) setDownloadUrls(res *search.Result) *search.Result {
+	base, err := neturl.Parse(a.baseUrl + internal.UrlTorrents)
+	if err != nil {
+		return res
+	}
+	key := a.getKey()
+	for _, t := range res.Torrents {
+		if t.DownloadUrl == "" && key != "" && t.Id != "" {
+			t.DownloadUrl = fmt.Sprintf("?action=download&id=%s&key=%s", t.Id, key)
+		}
+		if u, err := base.Parse(t.DownloadUrl); t.DownloadUrl != "" && err == nil {
+			t.DownloadUrl = u.String()
+		}
+	}
+	return res
+}
+
+func (a *api) getKey() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.key
+}
+
+func (a *api) setKey(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.key = key
+}
+
+func (a *api) canRelogin() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.auth != nil
+}
+
+func (a *api) loginRequired() error {
+	if a.expired.Load() {
+		return ErrSessionExpired
+	}
+	return ErrUserNotLoggedIn
+}
+
+// validateSession confirms or rejects a restored session, based on the first
+// response received after restoring it. Downloads and feeds are authorized by
+// the key instead of the session, so they tell nothing about the session.
+func (a *api) validateSession(op string, res *http.Response) {
+	if !a.restored.Load() || op == internal.OpDownload || op == internal.OpRss {
+		return
+	}
+	if internal.IsLoginRequired(res) {
+		a.restored.Store(false)
+		a.expired.Store(true)
+	} else if res.StatusCode == http.StatusOK {
+		a.restored.Store(false)
+	}
+}
+
+func (a *api) getDocument(ctx context.Context, op string, url string) (*html.Node, error) {
+	return a.fetchDocument(ctx, op, func() (*http.Request, error) {
+		return newGet(ctx, url)
+	})
+}
+
+func (a *api) fetchDocument(ctx context.Context, op string, build func() (*http.Request, error)) (*html.Node, error) {
+	res, err := a.fetch(ctx, op, build)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return html.Parse(res.Body)
+}
+
+// fetch sends the request, and turns a redirect to the login page,
+// or any other unexpected status code into an error.
+func (a *api) fetch(ctx context.Context, op string, build func() (*http.Request, error)) (*http.Response, error) {
+	res, err := a.send(ctx, op, build)
+	if err != nil {
+		return nil, err
+	}
+	if internal.IsLoginRequired(res) {
+		res.Body.Close()
+		return nil, a.loginRequired()
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, &StatusError{Op: op, Url: internal.RedactKey(res.Request.URL), StatusCode: res.StatusCode}
+	}
+	return res, nil
+}
+
+// send builds and sends a request. When auto login is enabled, and the
+// response requires a login, it logs in again and replays the request once.
+func (a *api) send(ctx context.Context, op string, build func() (*http.Request, error)) (*http.Response, error) {
+	gen := a.gen.Load()
+	res, err := a.do(ctx, op, build)
+	if err != nil || !internal.IsLoginRequired(res) || !a.canRelogin() {
+		return res, err
+	}
+	res.Body.Close()
+	a.logger.InfoContext(ctx, "login required, logging in again", "op", op)
+	if err := a.relogin(ctx, gen); err != nil {
+		return nil, err
+	}
+	return a.do(ctx, op, build)
+}
+
+// do builds and sends a request, and retries transient failures
+// according to the retry policy, rebuilding the request for every attempt.
+func (a *api) do(ctx context.Context, op string, build func() (*http.Request, error)) (*http.Response, error) {
+	a.mu.RLock()
+	policy := a.retryPolicy
+	a.mu.RUnlock()
+	for attempt := 1; ; attempt++ {
+		req, err := build()
+		if err != nil {
+			return nil, err
+		}
+		res, err := a.roundTrip(ctx, op, req)
+		if policy == nil || attempt >= policy.MaxAttempts || !isRetryable(ctx, policy, res, err) {
+			return res, err
+		}
+		delay := policy.Delay(attempt)
+		if res != nil {
+			after := retry.RetryAfter(res)
+			if policy.MaxDelay > 0 && after > policy.MaxDelay {
+				// the site asks for a longer pause than the policy allows
+				return res, err
+			}
+			delay = max(delay, after)
+			res.Body.Close()
+		}
+		a.logger.InfoContext(ctx, "retrying request", "op", op, "attempt", attempt+1, "delay", delay)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, &RequestError{Op: op, Url: internal.RedactKey(req.URL), Err: err}
+		}
+	}
+}
+
+// roundTrip sends the request, and wraps transport errors into a RequestError.
+// A cancelled or expired context is wrapped as the context error itself.
+func (a *api) roundTrip(ctx context.Context, op string, req *http.Request) (*http.Response, error) {
+	if err := a.wait(ctx, op); err != nil {
+		return nil, &RequestError{Op: op, Url: internal.RedactKey(req.URL), Err: err}
+	}
+	for key, values := range a.header {
+		req.Header[key] = values
+	}
+	url := internal.RedactKey(req.URL)
+	start := time.Now()
+	res, err := a.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		a.logger.DebugContext(ctx, "request failed", "op", op, "method", req.Method, "url", url, "error", err)
+		return nil, &RequestError{Op: op, Url: url, Err: err}
+	}
+	a.logger.DebugContext(ctx, "request", "op", op, "method", req.Method, "url", url,
+		"status", res.StatusCode, "duration", time.Since(start))
+	a.validateSession(op, res)
+	return res, nil
+}
+
+func (a *api) wait(ctx context.Context, op string) error {
+	a.mu.RLock()
+	limiter := a.limiter
+	if op == internal.OpDownload && a.downloadLimiter != nil {
+		limiter = a.downloadLimiter
+	}
+	a.mu.RUnlock()
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+func validateTorrent(id string, data []byte) error {
+	if _, err := torrent.Parse(data); err == nil {
+		return nil
+	}
+	return parseDownloadError(id, bytes.NewReader(data))
+}
+
+func validateTorrentFile(id string, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return validateTorrent(id, data)
+}
+
+// parseDownloadError explains the page returned instead of a torrent.
+func parseDownloadError(id string, r io.Reader) error {
+	doc, err := html.Parse(io.LimitReader(r, maxErrorPageSize))
+	if err != nil {
+		return &DownloadError{Id: id, Reason: download.Unknown}
+	}
+	reason, msg := download.ParseFailure(doc)
+	return &DownloadError{Id: id, Reason: reason, Message: msg}
+}
+
+func isRetryable(ctx context.Context, policy *retry.Policy, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return policy.RetryError(err)
+	}
+	return policy.RetryStatus(res.StatusCode)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newGet(ctx context.Context, url string) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+}
+
+func newPostForm(ctx context.Context, url string, form neturl.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
 func initCookieJar(client *http.Client) {
 	jar, _ := cookiejar.New(nil)
 	client.Jar = jar
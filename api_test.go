@@ -1,11 +1,28 @@
 package ngore
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gar-r/ngore/details"
+	"github.com/gar-r/ngore/download"
 	"github.com/gar-r/ngore/internal"
 	"github.com/gar-r/ngore/login"
+	"github.com/gar-r/ngore/ratelimit"
+	"github.com/gar-r/ngore/retry"
 	"github.com/gar-r/ngore/search"
+	"github.com/gar-r/ngore/session"
+	"iter"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -20,9 +37,15 @@ func TestNewApi(t *testing.T) {
 	})
 
 	t.Run("redirect is disabled", func(t *testing.T) {
+		ng := New(&http.Client{}, "baseUrl").(*api)
+		assert.Equal(t, http.ErrUseLastResponse, ng.client.CheckRedirect(nil, nil))
+	})
+
+	t.Run("caller's client not modified", func(t *testing.T) {
 		client := &http.Client{}
 		_ = New(client, "baseUrl")
-		assert.Equal(t, http.ErrUseLastResponse, client.CheckRedirect(nil, nil))
+		assert.Nil(t, client.CheckRedirect)
+		assert.Nil(t, client.Jar)
 	})
 
 	t.Run("cookie jar initialized", func(t *testing.T) {
@@ -100,7 +123,7 @@ func TestApi_Login(t *testing.T) {
 		defer server.Close()
 		ng := apiWithMockClient(server)
 		err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass"})
-		assert.ErrorContains(t, err, internal.ErrLoginInvalidCredentials)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
 	})
 
 	t.Run("server error", func(t *testing.T) {
@@ -110,7 +133,7 @@ func TestApi_Login(t *testing.T) {
 		defer server.Close()
 		ng := apiWithMockClient(server)
 		err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass"})
-		assert.ErrorContains(t, err, internal.ErrLoginUnexpectedResponse)
+		assert.ErrorIs(t, err, ErrUnexpectedLoginResponse)
 	})
 
 	t.Run("other error", func(t *testing.T) {
@@ -121,6 +144,85 @@ func TestApi_Login(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("two-factor authentication", func(t *testing.T) {
+
+		// twoFactorServer asks for a code, and accepts the login only with the expected one
+		twoFactorServer := func(expected string) *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != internal.UrlLogin {
+					_, _ = w.Write([]byte(`<link rel="alternate" href="/rss.php?key=abc123">`))
+					return
+				}
+				_ = r.ParseForm()
+				if !r.Form.Has("2factor") {
+					_, _ = w.Write([]byte(`<form><input type="text" name="2factor"></form>`))
+					return
+				}
+				if r.Form.Get("2factor") != expected {
+					http.Redirect(w, r, "https://example.com?problema=1", http.StatusFound)
+					return
+				}
+				http.Redirect(w, r, "https://example.com/index.php", http.StatusFound)
+			}))
+		}
+
+		t.Run("code accepted", func(t *testing.T) {
+			server := twoFactorServer("123456")
+			defer server.Close()
+			ng := apiWithMockClient(server)
+			err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass", TwoFactor: login.StaticCode("123456")})
+			assert.NoError(t, err)
+			assert.Equal(t, "abc123", ng.(*api).key)
+		})
+
+		t.Run("code from callback", func(t *testing.T) {
+			server := twoFactorServer("123456")
+			defer server.Close()
+			ng := apiWithMockClient(server)
+			prompt := login.CodeFunc(func() (string, error) {
+				return "123456", nil
+			})
+			err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass", TwoFactor: prompt})
+			assert.NoError(t, err)
+		})
+
+		t.Run("invalid code", func(t *testing.T) {
+			server := twoFactorServer("123456")
+			defer server.Close()
+			ng := apiWithMockClient(server)
+			err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass", TwoFactor: login.StaticCode("000000")})
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+
+		t.Run("code provider missing", func(t *testing.T) {
+			server := twoFactorServer("123456")
+			defer server.Close()
+			ng := apiWithMockClient(server)
+			err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass"})
+			assert.ErrorIs(t, err, ErrTwoFactorCodeRequired)
+		})
+
+		t.Run("empty code", func(t *testing.T) {
+			server := twoFactorServer("123456")
+			defer server.Close()
+			ng := apiWithMockClient(server)
+			err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass", TwoFactor: login.StaticCode("")})
+			assert.ErrorIs(t, err, ErrTwoFactorCodeRequired)
+		})
+
+		t.Run("code provider error", func(t *testing.T) {
+			server := twoFactorServer("123456")
+			defer server.Close()
+			ng := apiWithMockClient(server)
+			prompt := login.CodeFunc(func() (string, error) {
+				return "", errors.New("test error")
+			})
+			err := ng.Login(&login.BasicAuth{UserName: "user", Password: "pass", TwoFactor: prompt})
+			assert.ErrorContains(t, err, "test error")
+		})
+
+	})
+
 }
 
 func TestApi_Search(t *testing.T) {
@@ -132,7 +234,7 @@ func TestApi_Search(t *testing.T) {
 		defer server.Close()
 		api := apiWithMockClient(server)
 		_, err := api.Search(&search.Params{})
-		assert.ErrorContains(t, err, internal.ErrUserNotLoggedIn)
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
 	})
 
 	t.Run("search api server error", func(t *testing.T) {
@@ -145,6 +247,24 @@ func TestApi_Search(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("download urls", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`
+			<div class="box_torrent"><div class="torrent_txt"><a href="torrents.php?action=details&id=1"></a></div></div>
+			<div class="box_torrent">
+				<div class="torrent_txt"><a href="torrents.php?action=details&id=2"></a></div>
+				<a href="torrents.php?action=download&id=2&key=row"></a>
+			</div>`))
+		}))
+		defer server.Close()
+		api, err := NewWithOptions(server.URL, WithClient(server.Client()), WithSession(&session.Session{Key: "abc"}))
+		assert.NoError(t, err)
+		res, err := api.Search(&search.Params{})
+		assert.NoError(t, err)
+		assert.Equal(t, server.URL+"/torrents.php?action=download&id=1&key=abc", res.Torrents[0].DownloadUrl)
+		assert.Equal(t, server.URL+"/torrents.php?action=download&id=2&key=row", res.Torrents[1].DownloadUrl)
+	})
+
 	t.Run("search api network error", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -157,6 +277,247 @@ func TestApi_Search(t *testing.T) {
 
 }
 
+func TestApi_SearchAll(t *testing.T) {
+
+	collect := func(seq iter.Seq2[*search.Torrent, error]) ([]string, error) {
+		ids := make([]string, 0)
+		for tr, err := range seq {
+			if err != nil {
+				return ids, err
+			}
+			ids = append(ids, tr.Id)
+		}
+		return ids, nil
+	}
+
+	t.Run("all pages", func(t *testing.T) {
+		server := searchServer(3, 2)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		params := &search.Params{}
+		ids, err := collect(api.SearchAll(params, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"11", "12", "21", "22", "31", "32"}, ids)
+		assert.Equal(t, 0, params.Page)
+	})
+
+	t.Run("start page", func(t *testing.T) {
+		server := searchServer(3, 2)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		params := &search.Params{Page: 2}
+		ids, err := collect(api.SearchAll(params, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"21", "22", "31", "32"}, ids)
+		assert.Equal(t, 2, params.Page)
+	})
+
+	t.Run("max results", func(t *testing.T) {
+		var requests atomic.Int32
+		server := searchServer(3, 2, &requests)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		ids, err := collect(api.SearchAll(&search.Params{}, &search.Limit{MaxResults: 3}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"11", "12", "21"}, ids)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("max pages", func(t *testing.T) {
+		var requests atomic.Int32
+		server := searchServer(3, 2, &requests)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		ids, err := collect(api.SearchAll(&search.Params{}, &search.Limit{MaxPages: 2}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"11", "12", "21", "22"}, ids)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		var requests atomic.Int32
+		server := searchServer(3, 2, &requests)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		for range api.SearchAll(&search.Params{}, nil) {
+			break
+		}
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.FormValue("oldal") == "2" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(searchPage(1, 3, 2)))
+		}))
+		defer server.Close()
+		api := apiWithMockClient(server)
+		count := 0
+		for tr, err := range api.SearchAll(&search.Params{}, nil) {
+			count++
+			if count > 2 {
+				assert.Nil(t, tr)
+				assert.Error(t, err)
+			}
+		}
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("login required", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+		}))
+		defer server.Close()
+		api := apiWithMockClient(server)
+		_, err := collect(api.SearchAll(&search.Params{}, nil))
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+	})
+
+}
+
+func TestApi_SearchConcurrent(t *testing.T) {
+
+	ids := func(torrents []*search.Torrent) []string {
+		result := make([]string, 0, len(torrents))
+		for _, tr := range torrents {
+			result = append(result, tr.Id)
+		}
+		return result
+	}
+
+	t.Run("pages in order", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.FormValue("oldal"))
+			// later pages answer sooner
+			time.Sleep(time.Duration(5-page) * 10 * time.Millisecond)
+			_, _ = w.Write([]byte(searchPage(page, 4, 2)))
+		}))
+		defer server.Close()
+		api := apiWithMockClient(server)
+		params := &search.Params{}
+		torrents, err := api.SearchConcurrent(params, nil, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"11", "12", "21", "22", "31", "32", "41", "42"}, ids(torrents))
+		assert.Equal(t, 0, params.Page)
+	})
+
+	t.Run("duplicates removed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.FormValue("oldal"))
+			html := searchPage(page, 2, 2)
+			if page == 2 {
+				// a new upload shifted the last result of the first page
+				html = strings.Replace(html, "id=21", "id=12", 1)
+			}
+			_, _ = w.Write([]byte(html))
+		}))
+		defer server.Close()
+		api := apiWithMockClient(server)
+		torrents, err := api.SearchConcurrent(&search.Params{}, nil, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"11", "12", "22"}, ids(torrents))
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		var running, peak atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			page, _ := strconv.Atoi(r.FormValue("oldal"))
+			_, _ = w.Write([]byte(searchPage(page, 10, 1)))
+		}))
+		defer server.Close()
+		api := apiWithMockClient(server)
+		torrents, err := api.SearchConcurrent(&search.Params{}, nil, 3)
+		assert.NoError(t, err)
+		assert.Len(t, torrents, 10)
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		var requests atomic.Int32
+		server := searchServer(5, 2, &requests)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		torrents, err := api.SearchConcurrent(&search.Params{Page: 2}, &search.Limit{MaxPages: 2, MaxResults: 3}, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"21", "22", "31"}, ids(torrents))
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("only the pages needed for max results", func(t *testing.T) {
+		var requests atomic.Int32
+		server := searchServer(40, 4, &requests)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		torrents, err := api.SearchConcurrent(&search.Params{}, &search.Limit{MaxResults: 10}, 4)
+		assert.NoError(t, err)
+		assert.Len(t, torrents, 10)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("max results on the first page", func(t *testing.T) {
+		var requests atomic.Int32
+		server := searchServer(40, 4, &requests)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		torrents, err := api.SearchConcurrent(&search.Params{}, &search.Limit{MaxResults: 3}, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"11", "12", "13"}, ids(torrents))
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("single page", func(t *testing.T) {
+		var requests atomic.Int32
+		server := searchServer(1, 2, &requests)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		torrents, err := api.SearchConcurrent(&search.Params{}, nil, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"11", "12"}, ids(torrents))
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.FormValue("oldal"))
+			if page == 3 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(searchPage(page, 5, 2)))
+		}))
+		defer server.Close()
+		api := apiWithMockClient(server)
+		torrents, err := api.SearchConcurrent(&search.Params{}, nil, 2)
+		assert.Error(t, err)
+		assert.Nil(t, torrents)
+	})
+
+	t.Run("respects rate limit", func(t *testing.T) {
+		server := searchServer(4, 1)
+		defer server.Close()
+		api := apiWithMockClient(server)
+		api.SetRateLimit(ratelimit.New(ratelimit.Config{MinSpacing: 20 * time.Millisecond}))
+		start := time.Now()
+		torrents, err := api.SearchConcurrent(&search.Params{}, nil, 4)
+		assert.NoError(t, err)
+		assert.Len(t, torrents, 4)
+		assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	})
+
+}
+
 func TestApi_Activity(t *testing.T) {
 
 	t.Run("activity api login required", func(t *testing.T) {
@@ -166,7 +527,7 @@ func TestApi_Activity(t *testing.T) {
 		defer server.Close()
 		api := apiWithMockClient(server)
 		_, err := api.Activity()
-		assert.ErrorContains(t, err, internal.ErrUserNotLoggedIn)
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
 	})
 
 	t.Run("activity api network error", func(t *testing.T) {
@@ -191,11 +552,15 @@ func TestApi_Activity(t *testing.T) {
 
 }
 
+const testTorrent = "d4:infod4:name4:testee"
+
+const quotaPage = `<html><body><div class="hibauzenet">Elérted a napi letöltési limitet!</div></body></html>`
+
 func TestApi_Download(t *testing.T) {
 
 	t.Run("download api returns bytes", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			_, _ = w.Write([]byte(`test bytes`))
+			_, _ = w.Write([]byte(testTorrent))
 			w.WriteHeader(http.StatusOK)
 		}))
 		defer server.Close()
@@ -203,7 +568,7 @@ func TestApi_Download(t *testing.T) {
 		a.(*api).key = "foo"
 		res, err := a.Download("id")
 		assert.NoError(t, err)
-		assert.Equal(t, "test bytes", string(res))
+		assert.Equal(t, testTorrent, string(res))
 	})
 
 	t.Run("download api server error", func(t *testing.T) {
@@ -228,6 +593,33 @@ func TestApi_Download(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("html error page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(quotaPage))
+		}))
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.(*api).key = "foo"
+		_, err := a.Download("id")
+		var downloadErr *DownloadError
+		assert.ErrorAs(t, err, &downloadErr)
+		assert.Equal(t, "id", downloadErr.Id)
+		assert.Equal(t, download.QuotaExceeded, downloadErr.Reason)
+	})
+
+	t.Run("truncated torrent", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(testTorrent[:10]))
+		}))
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.(*api).key = "foo"
+		_, err := a.Download("id")
+		var downloadErr *DownloadError
+		assert.ErrorAs(t, err, &downloadErr)
+		assert.Equal(t, download.Unknown, downloadErr.Reason)
+	})
+
 	t.Run("download with api key missing", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -241,6 +633,236 @@ func TestApi_Download(t *testing.T) {
 
 }
 
+func TestApi_DownloadTo(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("id") == "quota" {
+			_, _ = w.Write([]byte(quotaPage))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-bittorrent")
+		w.Header().Set("Content-Disposition", `attachment; filename="test.torrent"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(testTorrent)))
+		_, _ = w.Write([]byte(testTorrent))
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+	a.(*api).key = "foo"
+
+	t.Run("stream to writer", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		info, err := a.DownloadTo("1", buf)
+		assert.NoError(t, err)
+		assert.Equal(t, testTorrent, buf.String())
+		assert.Equal(t, &download.Info{
+			FileName:      "test.torrent",
+			ContentLength: int64(len(testTorrent)),
+			ContentType:   "application/x-bittorrent",
+		}, info)
+	})
+
+	t.Run("download error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		_, err := a.DownloadTo("missing", buf)
+		assert.Error(t, err)
+		assert.Empty(t, buf.Bytes())
+	})
+
+	t.Run("html error page", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		_, err := a.DownloadTo("quota", buf)
+		var downloadErr *DownloadError
+		assert.ErrorAs(t, err, &downloadErr)
+		assert.Equal(t, download.QuotaExceeded, downloadErr.Reason)
+		assert.Empty(t, buf.Bytes())
+	})
+
+}
+
+func TestApi_DownloadFile(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("id") {
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "quota":
+			_, _ = w.Write([]byte(quotaPage))
+		case "invalid":
+			_, _ = w.Write([]byte(`d4:infod`))
+		case "unnamed":
+			_, _ = w.Write([]byte(testTorrent))
+		default:
+			w.Header().Set("Content-Disposition", `attachment; filename="test.torrent"`)
+			_, _ = w.Write([]byte(testTorrent))
+		}
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+	a.(*api).key = "foo"
+
+	t.Run("download to file path", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.torrent")
+		info, err := a.DownloadFile("1", path)
+		assert.NoError(t, err)
+		assert.Equal(t, path, info.Path)
+		b, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, testTorrent, string(b))
+		assertOnlyFiles(t, dir, "out.torrent")
+	})
+
+	t.Run("download to directory", func(t *testing.T) {
+		dir := t.TempDir()
+		info, err := a.DownloadFile("1", dir)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "test.torrent"), info.Path)
+		assertOnlyFiles(t, dir, "test.torrent")
+	})
+
+	t.Run("file readable by others", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("file modes are not supported")
+		}
+		path := filepath.Join(t.TempDir(), "out.torrent")
+		_, err := a.DownloadFile("1", path)
+		assert.NoError(t, err)
+		fi, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o644), fi.Mode().Perm())
+	})
+
+	t.Run("file name from torrent id", func(t *testing.T) {
+		dir := t.TempDir()
+		info, err := a.DownloadFile("unnamed", dir)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "unnamed.torrent"), info.Path)
+	})
+
+	t.Run("failed download leaves no file", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := a.DownloadFile("missing", filepath.Join(dir, "out.torrent"))
+		assert.Error(t, err)
+		assertOnlyFiles(t, dir)
+	})
+
+	t.Run("error page leaves no file", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := a.DownloadFile("quota", filepath.Join(dir, "out.torrent"))
+		var downloadErr *DownloadError
+		assert.ErrorAs(t, err, &downloadErr)
+		assertOnlyFiles(t, dir)
+	})
+
+	t.Run("invalid torrent leaves no file", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := a.DownloadFile("invalid", filepath.Join(dir, "out.torrent"))
+		var downloadErr *DownloadError
+		assert.ErrorAs(t, err, &downloadErr)
+		assertOnlyFiles(t, dir)
+	})
+
+	t.Run("existing file replaced", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.torrent")
+		assert.NoError(t, os.WriteFile(path, []byte(`old`), 0644))
+		_, err := a.DownloadFile("1", path)
+		assert.NoError(t, err)
+		b, _ := os.ReadFile(path)
+		assert.Equal(t, testTorrent, string(b))
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := a.DownloadFile("1", filepath.Join(t.TempDir(), "foo", "out.torrent"))
+		assert.Error(t, err)
+	})
+
+}
+
+func TestApi_Magnet(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") == "quota" {
+			_, _ = w.Write([]byte(quotaPage))
+			return
+		}
+		tracker := "https://t.ncore.sh/" + strings.Repeat("a", 32) + "/a"
+		_, _ = fmt.Fprintf(w, "d8:announce%d:%s4:infod6:lengthi5e4:name4:testee", len(tracker), tracker)
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+	a.(*api).key = "foo"
+
+	t.Run("magnet with passkey", func(t *testing.T) {
+		m, err := a.Magnet("1", false)
+		assert.NoError(t, err)
+		assert.Regexp(t, `^magnet:\?xt=urn:btih:[0-9a-f]{40}&dn=test&xl=5&tr=https%3A%2F%2Ft.ncore.sh%2Fa{32}%2Fa$`, m)
+	})
+
+	t.Run("magnet without passkey", func(t *testing.T) {
+		m, err := a.Magnet("1", true)
+		assert.NoError(t, err)
+		assert.Regexp(t, `&tr=https%3A%2F%2Ft.ncore.sh%2Fa$`, m)
+	})
+
+	t.Run("download failed", func(t *testing.T) {
+		_, err := a.Magnet("quota", false)
+		var downloadErr *DownloadError
+		assert.ErrorAs(t, err, &downloadErr)
+	})
+
+}
+
+func TestApi_Rss(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != internal.UrlRss || r.URL.Query().Get("key") != "foo" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<rss><channel><item><title>%s</title>`+
+			`<link>https://ncore.pro/torrents.php?action=download&amp;id=1&amp;key=foo</link></item></channel></rss>`,
+			r.URL.Query().Get("tipus"))
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+	a.(*api).key = "foo"
+
+	t.Run("whole feed", func(t *testing.T) {
+		f, err := a.Rss()
+		assert.NoError(t, err)
+		assert.Len(t, f.Items, 1)
+		assert.Equal(t, "1", f.Items[0].Id)
+	})
+
+	t.Run("category feed", func(t *testing.T) {
+		f, err := a.Rss(search.MovieHdHu)
+		assert.NoError(t, err)
+		assert.Equal(t, "hd_hun", f.Items[0].Title)
+	})
+
+	t.Run("key missing", func(t *testing.T) {
+		_, err := apiWithMockClient(server).Rss()
+		assert.ErrorIs(t, err, ErrApiKeyEmpty)
+	})
+
+}
+
+func assertOnlyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	actual := make([]string, 0)
+	for _, e := range entries {
+		actual = append(actual, e.Name())
+	}
+	assert.ElementsMatch(t, names, actual)
+}
+
 func TestApi_Details(t *testing.T) {
 
 	t.Run("details client error", func(t *testing.T) {
@@ -260,7 +882,7 @@ func TestApi_Details(t *testing.T) {
 		defer server.Close()
 		api := apiWithMockClient(server)
 		_, err := api.Details("foo")
-		assert.ErrorContains(t, err, internal.ErrUserNotLoggedIn)
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
 	})
 
 	t.Run("details unexpected status code", func(t *testing.T) {
@@ -273,6 +895,38 @@ func TestApi_Details(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("other versions", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			switch {
+			case r.URL.Path == internal.UrlTorrents:
+				_, _ = w.Write([]byte(`<a onclick="other_versions('otherlist', '0120844', '1', 1);">Mutat</a>`))
+			case q.Get("action") == "other_versions" && q.Get("id") == "1" && q.Get("fid") == "0120844":
+				_, _ = w.Write([]byte(`<div class="box_torrent"><a href="torrents.php?action=details&id=2">other</a></div>`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		d, err := apiWithMockClient(server).Details("1")
+		assert.NoError(t, err)
+		assert.Equal(t, []*details.Version{{Id: "2", Title: "other"}}, d.OtherVersions)
+	})
+
+	t.Run("other versions failed, details returned", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == internal.UrlTorrents {
+				_, _ = w.Write([]byte(`<a onclick="other_versions('otherlist', '0120844', '1', 1);">Mutat</a>`))
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		d, err := apiWithMockClient(server).Details("1")
+		assert.NoError(t, err)
+		assert.Empty(t, d.OtherVersions)
+	})
+
 	t.Run("details parsing", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write([]byte(`<html></html>`))
@@ -287,6 +941,865 @@ func TestApi_Details(t *testing.T) {
 
 }
 
-func apiWithMockClient(mockServer *httptest.Server) Api {
-	return New(mockServer.Client(), mockServer.URL)
+func TestApi_Files(t *testing.T) {
+
+	t.Run("file list", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != internal.UrlAjax || r.URL.Query().Get("action") != "files" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `<table><tr><td>%s.mkv</td><td>1 KiB</td></tr></table>`, r.URL.Query().Get("id"))
+		}))
+		defer server.Close()
+		files, err := apiWithMockClient(server).Files("123")
+		assert.NoError(t, err)
+		assert.Equal(t, []*details.File{{Path: "123.mkv", Size: 1024}}, files)
+	})
+
+	t.Run("invalid response code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+		_, err := apiWithMockClient(server).Files("123")
+		var statusErr *StatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, OpFiles, statusErr.Op)
+	})
+
+}
+
+func TestApi_NfoAndComments(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("id") != "1":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == internal.UrlAjax && q.Get("action") == "nfo":
+			_, _ = w.Write([]byte(`<pre>nfo text</pre>`))
+		case r.URL.Path == internal.UrlTorrents && q.Get("action") == "details":
+			_, _ = fmt.Fprintf(w, `<div id="hozzaszolasok"><div class="hozzaszolas"><div class="hsz_nev">user</div>`+
+				`<div class="hsz_szoveg">page %s</div></div></div>`+
+				`<a href="torrents.php?action=details&id=1&oldal=2">2</a>`, q.Get("oldal"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+
+	t.Run("nfo", func(t *testing.T) {
+		nfo, err := a.Nfo("1")
+		assert.NoError(t, err)
+		assert.Equal(t, "nfo text", nfo)
+	})
+
+	t.Run("first page of comments", func(t *testing.T) {
+		c, err := a.Comments("1", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, c.Page)
+		assert.True(t, c.HasMore)
+		assert.Equal(t, "page 1", c.Comments[0].Text)
+	})
+
+	t.Run("last page of comments", func(t *testing.T) {
+		c, err := a.Comments("1", 2)
+		assert.NoError(t, err)
+		assert.False(t, c.HasMore)
+		assert.Equal(t, "page 2", c.Comments[0].Text)
+	})
+
+	t.Run("invalid response code", func(t *testing.T) {
+		_, err := a.Nfo("2")
+		assert.Error(t, err)
+		_, err = a.Comments("2", 1)
+		assert.Error(t, err)
+	})
+
+}
+
+func TestApi_Context(t *testing.T) {
+
+	cancelled := func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+	a.(*api).key = "foo"
+
+	t.Run("login cancelled", func(t *testing.T) {
+		err := a.LoginContext(cancelled(), &login.BasicAuth{UserName: "user", Password: "pass"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("search cancelled", func(t *testing.T) {
+		_, err := a.SearchContext(cancelled(), &search.Params{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("activity cancelled", func(t *testing.T) {
+		_, err := a.ActivityContext(cancelled())
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("recommendations cancelled", func(t *testing.T) {
+		_, err := a.RecommendationsContext(cancelled())
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("details cancelled", func(t *testing.T) {
+		_, err := a.DetailsContext(cancelled(), "foo")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("download cancelled", func(t *testing.T) {
+		_, err := a.DownloadContext(cancelled(), "foo")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("key fetch cancelled during login", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == internal.UrlLogin {
+				cancel()
+				http.Redirect(w, r, "https://example.com/index.php", http.StatusFound)
+				return
+			}
+			_, _ = w.Write([]byte(`<link rel="alternate" href="/rss.php?key=abc123">`))
+		}))
+		defer server.Close()
+		ng := apiWithMockClient(server)
+		err := ng.LoginContext(ctx, &login.BasicAuth{UserName: "user", Password: "pass"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		_, err := a.SearchContext(ctx, &search.Params{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+}
+
+func TestApi_AutoLogin(t *testing.T) {
+
+	// sessionServer accepts requests with the cookie issued by the last login,
+	// and redirects to the login page after expire is called
+	type sessionServer struct {
+		*httptest.Server
+		mu     sync.Mutex
+		logins int
+		valid  string
+		reject bool
+		// twoFactor asks for a code on login, which is never accepted
+		twoFactor bool
+	}
+
+	newSessionServer := func() *sessionServer {
+		s := &sessionServer{}
+		s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			switch r.URL.Path {
+			case internal.UrlLogin:
+				s.logins++
+				if s.reject {
+					http.Redirect(w, r, internal.LocationLoginProblem, http.StatusFound)
+					return
+				}
+				if s.twoFactor {
+					_, _ = w.Write([]byte(`<form><input type="text" name="2factor"></form>`))
+					return
+				}
+				s.valid = fmt.Sprintf("session%d", s.logins)
+				http.SetCookie(w, &http.Cookie{Name: "PHPSESSID", Value: s.valid})
+				http.Redirect(w, r, "https://example.com/index.php", http.StatusFound)
+			case internal.UrlIndex:
+				_, _ = fmt.Fprintf(w, `<link rel="alternate" href="/rss.php?key=key%d">`, s.logins)
+			default:
+				c, err := r.Cookie("PHPSESSID")
+				if err != nil || c.Value != s.valid {
+					http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+					return
+				}
+				k := r.URL.Query().Get("key")
+				_, _ = fmt.Fprintf(w, "d3:key%d:%s4:infodee", len(k), k)
+			}
+		}))
+		return s
+	}
+
+	expire := func(s *sessionServer) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.valid = ""
+	}
+
+	auth := &login.BasicAuth{UserName: "user", Password: "pass"}
+
+	t.Run("login required without auto login", func(t *testing.T) {
+		server := newSessionServer()
+		defer server.Close()
+		ng := New(server.Client(), server.URL)
+		assert.NoError(t, ng.Login(auth))
+		expire(server)
+		_, err := ng.Activity()
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+	})
+
+	t.Run("request replayed after re-login", func(t *testing.T) {
+		server := newSessionServer()
+		defer server.Close()
+		ng := New(server.Client(), server.URL)
+		assert.NoError(t, ng.AutoLogin(auth))
+		expire(server)
+		_, err := ng.Activity()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, server.logins)
+	})
+
+	t.Run("key refreshed after re-login", func(t *testing.T) {
+		server := newSessionServer()
+		defer server.Close()
+		ng := New(server.Client(), server.URL)
+		assert.NoError(t, ng.AutoLogin(auth))
+		expire(server)
+		b, err := ng.Download("1")
+		assert.NoError(t, err)
+		assert.Equal(t, "d3:key4:key24:infodee", string(b))
+	})
+
+	t.Run("concurrent callers share a single re-login", func(t *testing.T) {
+		server := newSessionServer()
+		defer server.Close()
+		ng := New(server.Client(), server.URL)
+		assert.NoError(t, ng.AutoLogin(auth))
+		expire(server)
+		wg := sync.WaitGroup{}
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := ng.Activity()
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 2, server.logins)
+	})
+
+	t.Run("concurrent callers share a failed re-login", func(t *testing.T) {
+		server := newSessionServer()
+		defer server.Close()
+		ng := New(server.Client(), server.URL).(*api)
+		assert.NoError(t, ng.AutoLogin(auth))
+		expire(server)
+		server.mu.Lock()
+		server.reject = true
+		server.mu.Unlock()
+		wg := sync.WaitGroup{}
+		for range 10 {
+			wg.Go(func() {
+				// callers starting after the failure find auto login turned off
+				_, err := ng.Activity()
+				assert.True(t, errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrUserNotLoggedIn), err)
+			})
+		}
+		wg.Wait()
+		assert.Equal(t, 2, server.logins)
+		assert.False(t, ng.canRelogin())
+
+		// auto login is turned off, later requests do not try again
+		_, err := ng.Activity()
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+		assert.Equal(t, 2, server.logins)
+	})
+
+	t.Run("concurrent callers share a re-login missing the two-factor code", func(t *testing.T) {
+		server := newSessionServer()
+		defer server.Close()
+		ng := New(server.Client(), server.URL).(*api)
+		assert.NoError(t, ng.AutoLogin(auth))
+		expire(server)
+		server.mu.Lock()
+		server.twoFactor = true
+		server.mu.Unlock()
+		wg := sync.WaitGroup{}
+		for range 10 {
+			wg.Go(func() {
+				_, err := ng.Activity()
+				assert.True(t, errors.Is(err, ErrTwoFactorCodeRequired) || errors.Is(err, ErrUserNotLoggedIn), err)
+			})
+		}
+		wg.Wait()
+		assert.Equal(t, 2, server.logins)
+		assert.False(t, ng.canRelogin())
+
+		_, err := ng.Activity()
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+		assert.Equal(t, 2, server.logins)
+	})
+
+	t.Run("failed re-login", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+		}))
+		defer server.Close()
+		ng := New(server.Client(), server.URL).(*api)
+		ng.auth = auth
+		_, err := ng.Activity()
+		assert.ErrorIs(t, err, ErrUnexpectedLoginResponse)
+	})
+
+	t.Run("failed auto login", func(t *testing.T) {
+		ng := Default("foo").(*api)
+		assert.Error(t, ng.AutoLogin(&login.BasicAuth{UserName: "user"}))
+		assert.Nil(t, ng.auth)
+	})
+
+}
+
+func TestApi_RateLimit(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("action") == "download" {
+			_, _ = w.Write([]byte(testTorrent))
+			return
+		}
+		_, _ = w.Write([]byte(`<html></html>`))
+	}))
+	defer server.Close()
+
+	t.Run("requests paced", func(t *testing.T) {
+		a := apiWithMockClient(server)
+		a.SetRateLimit(ratelimit.New(ratelimit.Config{MinSpacing: 50 * time.Millisecond}))
+		begin := time.Now()
+		for range 3 {
+			_, err := a.Activity()
+			assert.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(begin), 100*time.Millisecond)
+	})
+
+	t.Run("separate download budget", func(t *testing.T) {
+		a := apiWithMockClient(server)
+		a.(*api).key = "foo"
+		a.SetRateLimit(ratelimit.New(ratelimit.Config{MinSpacing: time.Hour}))
+		a.SetDownloadRateLimit(ratelimit.New(ratelimit.Config{}))
+		_, err := a.Activity()
+		assert.NoError(t, err)
+		for range 3 {
+			_, err := a.Download("1")
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		a := apiWithMockClient(server)
+		a.SetRateLimit(ratelimit.New(ratelimit.Config{MinSpacing: time.Hour}))
+		_, err := a.Activity()
+		assert.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = a.ActivityContext(ctx)
+		var requestErr *RequestError
+		assert.ErrorAs(t, err, &requestErr)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+}
+
+func TestApi_Retry(t *testing.T) {
+
+	policy := &retry.Policy{
+		MaxAttempts:     3,
+		BaseDelay:       time.Millisecond,
+		RetryableStatus: []int{http.StatusServiceUnavailable},
+	}
+
+	// flakyServer fails with the given status the given number of times, before succeeding
+	flakyServer := func(failures int, status int, attempts *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if int(attempts.Add(1)) <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = w.Write([]byte(`<html></html>`))
+		}))
+	}
+
+	t.Run("no retries by default", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := flakyServer(1, http.StatusServiceUnavailable, attempts)
+		defer server.Close()
+		_, err := apiWithMockClient(server).Activity()
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("retryable status", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := flakyServer(2, http.StatusServiceUnavailable, attempts)
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.SetRetryPolicy(policy)
+		_, err := a.Activity()
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := flakyServer(5, http.StatusServiceUnavailable, attempts)
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.SetRetryPolicy(policy)
+		_, err := a.Activity()
+		var statusErr *StatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("retry after longer than max delay", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.SetRetryPolicy(&retry.Policy{
+			MaxAttempts:     3,
+			BaseDelay:       time.Millisecond,
+			MaxDelay:        time.Second,
+			RetryableStatus: []int{http.StatusServiceUnavailable},
+		})
+		start := time.Now()
+		_, err := a.Activity()
+		var statusErr *StatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		assert.Equal(t, int32(1), attempts.Load())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("status not retryable", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := flakyServer(1, http.StatusNotFound, attempts)
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.SetRetryPolicy(policy)
+		_, err := a.Activity()
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		a := apiWithMockClient(server)
+		server.Close()
+		attempts := 0
+		a.SetRetryPolicy(&retry.Policy{
+			MaxAttempts: 3,
+			RetryableError: func(err error) bool {
+				attempts++
+				return true
+			},
+		})
+		_, err := a.Activity()
+		var requestErr *RequestError
+		assert.ErrorAs(t, err, &requestErr)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("invalid credentials not retried", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			http.Redirect(w, r, "https://example.com?problema=1", http.StatusFound)
+		}))
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.SetRetryPolicy(retry.Default())
+		err := a.Login(&login.BasicAuth{UserName: "user", Password: "pass"})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("login retried", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == internal.UrlLogin && attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if r.URL.Path == internal.UrlLogin {
+				http.Redirect(w, r, "https://example.com/index.php", http.StatusFound)
+				return
+			}
+			_, _ = w.Write([]byte(`<link rel="alternate" href="/rss.php?key=abc123">`))
+		}))
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.SetRetryPolicy(policy)
+		err := a.Login(&login.BasicAuth{UserName: "user", Password: "pass"})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("cancelled during backoff", func(t *testing.T) {
+		attempts := &atomic.Int32{}
+		server := flakyServer(5, http.StatusServiceUnavailable, attempts)
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.SetRetryPolicy(&retry.Policy{
+			MaxAttempts:     3,
+			BaseDelay:       time.Hour,
+			RetryableStatus: []int{http.StatusServiceUnavailable},
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := a.ActivityContext(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+}
+
+func TestApi_Session(t *testing.T) {
+
+	t.Run("not logged in", func(t *testing.T) {
+		_, err := Default("https://example.com").Session()
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+	})
+
+	t.Run("export session", func(t *testing.T) {
+		server := loginServer()
+		defer server.Close()
+		ng := apiWithMockClient(server)
+		assert.NoError(t, ng.Login(&login.BasicAuth{UserName: "user", Password: "pass"}))
+		s, err := ng.Session()
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", s.Key)
+		assert.Equal(t, []*session.Cookie{{Name: "PHPSESSID", Value: "foo"}}, s.Cookies)
+	})
+
+}
+
+func TestRestore(t *testing.T) {
+
+	snapshot := &session.Session{
+		Key:     "abc123",
+		Cookies: []*session.Cookie{{Name: "PHPSESSID", Value: "foo"}},
+	}
+
+	t.Run("session cookies and key restored", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := r.Cookie("PHPSESSID")
+			if err != nil || c.Value != "foo" {
+				http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+				return
+			}
+			_, _ = w.Write([]byte(`<html></html>`))
+		}))
+		defer server.Close()
+		ng, err := Restore(server.Client(), server.URL, snapshot)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", ng.(*api).key)
+		_, err = ng.Activity()
+		assert.NoError(t, err)
+		s, err := ng.Session()
+		assert.NoError(t, err)
+		assert.Equal(t, snapshot, s)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+		}))
+		defer server.Close()
+		ng, err := Restore(server.Client(), server.URL, snapshot)
+		assert.NoError(t, err)
+		_, err = ng.Activity()
+		assert.ErrorIs(t, err, ErrSessionExpired)
+	})
+
+	t.Run("expired session after download", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// downloads are authorized by the key, even without a session
+			if r.URL.Query().Get("action") == "download" {
+				_, _ = w.Write([]byte(testTorrent))
+				return
+			}
+			http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+		}))
+		defer server.Close()
+		ng, err := Restore(server.Client(), server.URL, snapshot)
+		assert.NoError(t, err)
+		_, err = ng.Download("1")
+		assert.NoError(t, err)
+		_, err = ng.Activity()
+		assert.ErrorIs(t, err, ErrSessionExpired)
+	})
+
+	t.Run("expired session cleared by login", func(t *testing.T) {
+		server := loginServer()
+		defer server.Close()
+		ng, err := Restore(server.Client(), server.URL, snapshot)
+		assert.NoError(t, err)
+		ng.(*api).expired.Store(true)
+		assert.NoError(t, ng.Login(&login.BasicAuth{UserName: "user", Password: "pass"}))
+		assert.False(t, ng.(*api).expired.Load())
+	})
+
+	t.Run("invalid base url", func(t *testing.T) {
+		_, err := Restore(&http.Client{}, "://foo", snapshot)
+		assert.ErrorIs(t, err, ErrInvalidBaseUrl)
+	})
+
+}
+
+func TestApi_Bookmarks(t *testing.T) {
+
+	var actions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != internal.UrlBookmarks {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query()
+		if q.Has("action") {
+			actions = append(actions, q.Get("action")+" "+q.Get("id"))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<div class="box_torrent"><div class="torrent_txt">`+
+			`<a href="torrents.php?action=details&id=%s" title="foo"></a></div></div>`, q.Get("oldal"))
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+
+	t.Run("list bookmarks", func(t *testing.T) {
+		res, err := a.Bookmarks(2)
+		assert.NoError(t, err)
+		assert.Len(t, res.Torrents, 1)
+		assert.Equal(t, "2", res.Torrents[0].Id)
+		assert.Equal(t, "foo", res.Torrents[0].Title)
+	})
+
+	t.Run("first page by default", func(t *testing.T) {
+		res, err := a.Bookmarks(0)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", res.Torrents[0].Id)
+	})
+
+	t.Run("add and remove", func(t *testing.T) {
+		actions = nil
+		assert.NoError(t, a.AddBookmark("123"))
+		assert.NoError(t, a.RemoveBookmark("456"))
+		assert.Equal(t, []string{"add 123", "del 456"}, actions)
+	})
+
+	t.Run("update answered with a page", func(t *testing.T) {
+		pages := []string{
+			`<html><body><div class="hibauzenet">Nincs ilyen torrent!</div></body></html>`,
+			`<!DOCTYPE html><html><head><title>nCore</title></head><body>index</body></html>`,
+		}
+		for _, page := range pages {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(page))
+			}))
+			a := apiWithMockClient(server)
+			assert.ErrorIs(t, a.AddBookmark("1"), ErrBookmarkFailed)
+			assert.ErrorIs(t, a.RemoveBookmark("1"), ErrBookmarkFailed)
+			server.Close()
+		}
+	})
+
+	t.Run("user not logged in", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+		}))
+		defer server.Close()
+		a := apiWithMockClient(server)
+		_, err := a.Bookmarks(1)
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+		assert.ErrorIs(t, a.AddBookmark("1"), ErrUserNotLoggedIn)
+		assert.ErrorIs(t, a.RemoveBookmark("1"), ErrUserNotLoggedIn)
+	})
+
+}
+
+func TestApi_Profile(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != internal.UrlProfile {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		name := r.URL.Query().Get("id")
+		if name == "" {
+			name = "me"
+		}
+		_, _ = fmt.Fprintf(w, `<div class="dt">Felhasználónév:</div><div class="dd">%s</div>`+
+			`<div class="dt">Feltöltés:</div><div class="dd">1 KiB</div>`, name)
+	}))
+	defer server.Close()
+	a := apiWithMockClient(server)
+
+	t.Run("own profile", func(t *testing.T) {
+		p, err := a.Profile()
+		assert.NoError(t, err)
+		assert.Equal(t, "me", p.UserName)
+		assert.Equal(t, int64(1024), p.Uploaded)
+	})
+
+	t.Run("profile of another user", func(t *testing.T) {
+		p, err := a.UserProfile("123")
+		assert.NoError(t, err)
+		assert.Equal(t, "123", p.UserName)
+	})
+
+	t.Run("user not logged in", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, internal.LocationLogin, http.StatusFound)
+		}))
+		defer server.Close()
+		_, err := apiWithMockClient(server).Profile()
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+	})
+
+}
+
+func TestFromKey(t *testing.T) {
+
+	t.Run("download and rss without login", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Query().Get("key") != "abc123":
+				w.WriteHeader(http.StatusForbidden)
+			case r.URL.Path == internal.UrlRss:
+				_, _ = w.Write([]byte(`<rss><channel><item><title>foo</title></item></channel></rss>`))
+			default:
+				_, _ = w.Write([]byte(testTorrent))
+			}
+		}))
+		defer server.Close()
+		ng, err := FromKey(server.Client(), server.URL, "abc123")
+		assert.NoError(t, err)
+		b, err := ng.Download("1")
+		assert.NoError(t, err)
+		assert.Equal(t, testTorrent, string(b))
+		f, err := ng.Rss()
+		assert.NoError(t, err)
+		assert.Len(t, f.Items, 1)
+	})
+
+	t.Run("session operations rejected", func(t *testing.T) {
+		requests := &atomic.Int32{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+		}))
+		defer server.Close()
+		ng, err := FromKey(server.Client(), server.URL, "abc123")
+		assert.NoError(t, err)
+		_, err = ng.Search(&search.Params{})
+		assert.ErrorIs(t, err, ErrKeyOnly)
+		assert.ErrorIs(t, err, ErrUserNotLoggedIn)
+		_, err = ng.Activity()
+		assert.ErrorIs(t, err, ErrKeyOnly)
+		_, err = ng.Recommendations()
+		assert.ErrorIs(t, err, ErrKeyOnly)
+		_, err = ng.Details("1")
+		assert.ErrorIs(t, err, ErrKeyOnly)
+		_, err = ng.Session()
+		assert.ErrorIs(t, err, ErrKeyOnly)
+		_, err = ng.Bookmarks(1)
+		assert.ErrorIs(t, err, ErrKeyOnly)
+		assert.ErrorIs(t, ng.AddBookmark("1"), ErrKeyOnly)
+		_, err = ng.Profile()
+		assert.ErrorIs(t, err, ErrKeyOnly)
+		assert.Zero(t, requests.Load())
+	})
+
+	t.Run("login enables session operations", func(t *testing.T) {
+		server := loginServer()
+		defer server.Close()
+		ng, err := FromKey(server.Client(), server.URL, "old")
+		assert.NoError(t, err)
+		assert.NoError(t, ng.Login(&login.BasicAuth{UserName: "user", Password: "pass"}))
+		_, err = ng.Activity()
+		assert.NoError(t, err)
+		s, err := ng.Session()
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", s.Key)
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		_, err := FromKey(http.DefaultClient, "https://example.com", "")
+		assert.ErrorIs(t, err, ErrApiKeyEmpty)
+	})
+
+	t.Run("session takes precedence", func(t *testing.T) {
+		ng, err := NewWithOptions("https://example.com", WithKey("foo"), WithSession(&session.Session{Key: "bar"}))
+		assert.NoError(t, err)
+		assert.Equal(t, "bar", ng.(*api).key)
+		assert.False(t, ng.(*api).keyOnly.Load())
+	})
+
+}
+
+func loginServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == internal.UrlLogin {
+			http.SetCookie(w, &http.Cookie{Name: "PHPSESSID", Value: "foo"})
+			http.Redirect(w, r, "https://example.com/index.php", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(`<link rel="alternate" href="/rss.php?key=abc123">`))
+	}))
+}
+
+func apiWithMockClient(mockServer *httptest.Server) Api {
+	return New(mockServer.Client(), mockServer.URL)
+}
+
+// searchServer serves the given number of search result pages, each with perPage torrents
+func searchServer(pages int, perPage int, requests ...*atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, c := range requests {
+			c.Add(1)
+		}
+		page, _ := strconv.Atoi(r.FormValue("oldal"))
+		_, _ = w.Write([]byte(searchPage(page, pages, perPage)))
+	}))
+}
+
+func searchPage(page int, pages int, perPage int) string {
+	sb := &strings.Builder{}
+	for i := 1; i <= perPage; i++ {
+		_, _ = fmt.Fprintf(sb, `<div class="box_torrent"><div class="torrent_txt"><a href="torrents.php?action=details&id=%d%d" title="t"></a></div></div>`, page, i)
+	}
+	pageRange := func(p int) string {
+		return fmt.Sprintf("%d-%d", (p-1)*25+1, p*25)
+	}
+	sb.WriteString(`<div id="pager_bottom">`)
+	_, _ = fmt.Fprintf(sb, `<span class="active_link"><strong>%s</strong></span>`, pageRange(page))
+	if page < pages {
+		_, _ = fmt.Fprintf(sb, `<a id="nPa"><strong>%s</strong></a>`, pageRange(page+1))
+		_, _ = fmt.Fprintf(sb, `<a href="/torrents.php?oldal=%d"><strong>Utolsó</strong></a>`, pages)
+	}
+	sb.WriteString(`</div>`)
+	return sb.String()
 }
@@ -0,0 +1,125 @@
+package bencode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+
+	t.Run("values", func(t *testing.T) {
+		v, err := Decode([]byte("d4:listli1ei-2e3:fooe6:nestedd1:x4:spamee"))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"list":   []any{int64(1), int64(-2), "foo"},
+			"nested": map[string]any{"x": "spam"},
+		}, v)
+	})
+
+	t.Run("empty containers", func(t *testing.T) {
+		v, err := Decode([]byte("d1:ale1:bdee"))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": []any{}, "b": map[string]any{}}, v)
+	})
+
+	t.Run("binary string", func(t *testing.T) {
+		v, err := Decode([]byte("3:\x00\xff:"))
+		assert.NoError(t, err)
+		assert.Equal(t, "\x00\xff:", v)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		inputs := []string{
+			"",
+			"<html>",
+			"i12",
+			"i1x2e",
+			"ie",
+			"i-e",
+			"i-0e",
+			"i03e",
+			"i99999999999999999999e",
+			"03:foo",
+			"4:foo",
+			"99999999999999999999:x",
+			"l1:a",
+			"d1:a",
+			"di1e1:ae",
+			"d1:ae",
+			"i1ei2e",
+		}
+		for _, in := range inputs {
+			_, err := Decode([]byte(in))
+			var syntaxErr *SyntaxError
+			assert.ErrorAs(t, err, &syntaxErr, in)
+		}
+	})
+
+	t.Run("nesting too deep", func(t *testing.T) {
+		data := make([]byte, 0, 2*(maxDepth+2))
+		for range maxDepth + 2 {
+			data = append(data, 'l')
+		}
+		for range maxDepth + 2 {
+			data = append(data, 'e')
+		}
+		_, err := Decode(data)
+		assert.ErrorContains(t, err, "nesting too deep")
+	})
+
+}
+
+func TestLookup(t *testing.T) {
+
+	t.Run("raw value", func(t *testing.T) {
+		raw, ok, err := Lookup([]byte("d1:ai1e4:infod1:b1:c1:ali1eee1:zi2ee"), "info")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "d1:b1:c1:ali1eee", string(raw))
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, ok, err := Lookup([]byte("d1:ai1ee"), "info")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, in := range []string{"", "li1ee", "d4:infod", "d1:ai1eeX"} {
+			_, _, err := Lookup([]byte(in), "info")
+			assert.Error(t, err, in)
+		}
+	})
+
+}
+
+func TestEncode(t *testing.T) {
+
+	t.Run("round trip", func(t *testing.T) {
+		data := "d1:ai-1e1:bl3:fooi0ee1:cd1:xleee"
+		v, err := Decode([]byte(data))
+		assert.NoError(t, err)
+		b, err := Encode(v)
+		assert.NoError(t, err)
+		assert.Equal(t, data, string(b))
+	})
+
+	t.Run("keys sorted", func(t *testing.T) {
+		b, err := Encode(map[string]any{"b": 1, "a": true, "c": []byte("x")})
+		assert.NoError(t, err)
+		assert.Equal(t, "d1:ai1e1:bi1e1:c1:xe", string(b))
+	})
+
+	t.Run("string types", func(t *testing.T) {
+		b, err := Encode(map[string]any{"l": []string{"x", "yz"}, "m": map[string]string{"k": "v"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "d1:ll1:x2:yze1:md1:k1:vee", string(b))
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := Encode([]any{1.5})
+		assert.Error(t, err)
+	})
+
+}
@@ -0,0 +1,216 @@
+package bencode
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// maxDepth limits the nesting of lists and dictionaries,
+// so a malicious input can not exhaust the stack
+const maxDepth = 256
+
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("bencode: %s at offset %d", e.Msg, e.Offset)
+}
+
+// Decode decodes a single bencoded value. Integers are decoded as int64,
+// strings as string, lists as []any and dictionaries as map[string]any.
+func Decode(data []byte) (any, error) {
+	d := &decoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, d.error("trailing data")
+	}
+	return v, nil
+}
+
+// Lookup returns the raw bencoded value of a key in the top level dictionary,
+// exactly as it appears in the data.
+func Lookup(data []byte, key string) ([]byte, bool, error) {
+	d := &decoder{data: data}
+	if !d.peek('d') {
+		return nil, false, d.error("expected dictionary")
+	}
+	d.pos++
+	var raw []byte
+	found := false
+	for !d.peek('e') {
+		k, err := d.str()
+		if err != nil {
+			return nil, false, err
+		}
+		start := d.pos
+		if err := d.skip(1); err != nil {
+			return nil, false, err
+		}
+		if k == key {
+			raw, found = data[start:d.pos], true
+		}
+	}
+	d.pos++
+	if d.pos != len(data) {
+		return nil, false, d.error("trailing data")
+	}
+	return raw, found, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) error(msg string) error {
+	return &SyntaxError{Offset: d.pos, Msg: msg}
+}
+
+func (d *decoder) peek(c byte) bool {
+	return d.pos < len(d.data) && d.data[d.pos] == c
+}
+
+func (d *decoder) value(depth int) (any, error) {
+	if d.pos >= len(d.data) {
+		return nil, d.error("unexpected end of data")
+	}
+	if depth > maxDepth {
+		return nil, d.error("nesting too deep")
+	}
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		return d.integer()
+	case c == 'l':
+		return d.list(depth)
+	case c == 'd':
+		return d.dict(depth)
+	case c >= '0' && c <= '9':
+		return d.str()
+	}
+	return nil, d.error("invalid value")
+}
+
+// skip steps over a value without decoding it
+func (d *decoder) skip(depth int) error {
+	if d.pos >= len(d.data) {
+		return d.error("unexpected end of data")
+	}
+	if depth > maxDepth {
+		return d.error("nesting too deep")
+	}
+	switch c := d.data[d.pos]; {
+	case c == 'l' || c == 'd':
+		dict := c == 'd'
+		d.pos++
+		for !d.peek('e') {
+			if dict {
+				if _, err := d.str(); err != nil {
+					return err
+				}
+			}
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+		d.pos++
+		return nil
+	}
+	_, err := d.value(depth)
+	return err
+}
+
+func (d *decoder) integer() (int64, error) {
+	end := bytes.IndexByte(d.data[d.pos:], 'e')
+	if end < 0 {
+		return 0, d.error("unterminated integer")
+	}
+	digits := string(d.data[d.pos+1 : d.pos+end])
+	if !validInteger(digits) {
+		return 0, d.error("invalid integer")
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, d.error("integer out of range")
+	}
+	d.pos += end + 1
+	return n, nil
+}
+
+func (d *decoder) str() (string, error) {
+	colon := bytes.IndexByte(d.data[d.pos:], ':')
+	if colon < 0 {
+		return "", d.error("invalid string")
+	}
+	digits := string(d.data[d.pos : d.pos+colon])
+	if !validLength(digits) {
+		return "", d.error("invalid string length")
+	}
+	n, err := strconv.Atoi(digits)
+	start := d.pos + colon + 1
+	if err != nil || n > len(d.data)-start {
+		return "", d.error("string length out of range")
+	}
+	d.pos = start + n
+	return string(d.data[start:d.pos]), nil
+}
+
+func (d *decoder) list(depth int) ([]any, error) {
+	d.pos++
+	l := []any{}
+	for !d.peek('e') {
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, v)
+	}
+	d.pos++
+	return l, nil
+}
+
+func (d *decoder) dict(depth int) (map[string]any, error) {
+	d.pos++
+	m := map[string]any{}
+	for !d.peek('e') {
+		if d.pos >= len(d.data) {
+			return nil, d.error("unexpected end of data")
+		}
+		k, err := d.str()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	d.pos++
+	return m, nil
+}
+
+// validInteger rejects leading zeros and negative zero, which are not allowed by the spec
+func validInteger(s string) bool {
+	if len(s) > 0 && s[0] == '-' {
+		return validLength(s[1:]) && s != "-0"
+	}
+	return validLength(s)
+}
+
+func validLength(s string) bool {
+	if len(s) == 0 || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
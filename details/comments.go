@@ -0,0 +1,52 @@
+package details
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+// ParseComments parses the comments shown on the given page of the details page.
+func ParseComments(doc *html.Node, page int) *Comments {
+	result := &Comments{
+		Comments: make([]*Comment, 0),
+		Page:     page,
+		HasMore:  hasPage(doc, page+1),
+	}
+	container := parse.GetElementById(doc, "hozzaszolasok")
+	if container == nil {
+		return result
+	}
+	for _, n := range parse.GetElementsByClass(container, "hozzaszolas") {
+		c := &Comment{
+			Author: classText(n, "hsz_nev"),
+			Text:   classText(n, "hsz_szoveg"),
+		}
+		c.Posted, _ = parse.Time(classText(n, "hsz_datum"))
+		result.Comments = append(result.Comments, c)
+	}
+	return result
+}
+
+func classText(n *html.Node, class string) string {
+	e := parse.GetElementByClass(n, class)
+	if e == nil {
+		return ""
+	}
+	return parse.GetTextContent(e)
+}
+
+// hasPage looks for a link to the given page of the comments
+func hasPage(doc *html.Node, page int) bool {
+	param := fmt.Sprintf("oldal=%d", page)
+	for _, a := range parse.GetElementsByTag(doc, "a") {
+		href, _ := parse.FindAttr(a, "href")
+		if strings.Contains(href, param+"&") || strings.HasSuffix(href, param) ||
+			strings.Contains(href, param+"#") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package details
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gar-r/ngore/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseComments(t *testing.T) {
+
+	t.Run("comments with more pages", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div id="hozzaszolasok">
+		<div class="hozzaszolas">
+			<div class="hsz_nev"><a href="profile.php?id=1">someone</a></div>
+			<div class="hsz_datum">2021-06-10 08:00:19</div>
+			<div class="hsz_szoveg">The audio is <b>out of sync</b>.</div>
+		</div>
+		<div class="hozzaszolas">
+			<div class="hsz_nev">other</div>
+			<div class="hsz_szoveg">Thanks!</div>
+		</div>
+		</div>
+		<a href="torrents.php?action=details&id=1&oldal=21">21</a>
+		<a href="torrents.php?action=details&id=1&oldal=3#comments">3</a>`)
+		c := ParseComments(doc, 2)
+		assert.Equal(t, &Comments{
+			Comments: []*Comment{
+				{
+					Author: "someone",
+					Posted: time.Date(2021, 6, 10, 8, 0, 19, 0, parse.Location()),
+					Text:   "The audio is out of sync.",
+				},
+				{Author: "other", Text: "Thanks!"},
+			},
+			Page:    2,
+			HasMore: true,
+		}, c)
+	})
+
+	t.Run("no comments", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div id="hozzaszolasok">
+			<div class="teljes_oldalas">Ehhez a torrenthez még senki nem írt hozzászólást!</div>
+		</div>`)
+		c := ParseComments(doc, 1)
+		assert.Empty(t, c.Comments)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		doc := parse.MustParse(t, `<a href="torrents.php?action=details&id=1&oldal=21">21</a>`)
+		c := ParseComments(doc, 2)
+		assert.Empty(t, c.Comments)
+		assert.False(t, c.HasMore)
+	})
+
+}
+
+func TestParseNfo(t *testing.T) {
+
+	t.Run("nfo text", func(t *testing.T) {
+		doc := parse.MustParse(t, "<div><pre>\n  ___\n |   |  Release\n |___|<br>Audio: Hungarian</pre></div>")
+		assert.Equal(t, "  ___\n |   |  Release\n |___|\nAudio: Hungarian", ParseNfo(doc))
+	})
+
+	t.Run("nfo without pre", func(t *testing.T) {
+		doc := parse.MustParse(t, "Release<br>Audio: Hungarian")
+		assert.Equal(t, "Release\nAudio: Hungarian", ParseNfo(doc))
+	})
+
+	t.Run("no nfo", func(t *testing.T) {
+		doc := parse.MustParse(t, ``)
+		assert.Equal(t, "", ParseNfo(doc))
+	})
+
+}
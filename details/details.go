@@ -12,7 +12,9 @@ var detailLinkRegex = regexp.MustCompile(`.*\?(http.*)`)
 
 func ParseDetails(doc *html.Node) *Details {
 	result := &Details{
-		Type: parseType(doc),
+		Type:          parseType(doc),
+		Release:       parseRelease(doc),
+		OtherVersions: make([]*Version, 0),
 	}
 	switch result.Type {
 	case "sorozat":
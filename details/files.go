@@ -0,0 +1,26 @@
+package details
+
+import (
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+// ParseFiles parses the file list of a torrent. Every row lists a file,
+// with the path in the first, and the size in the last column.
+// Rows without a valid size, like the header, are skipped.
+func ParseFiles(doc *html.Node) []*File {
+	files := make([]*File, 0)
+	for _, row := range parse.GetElementsByTag(doc, "tr") {
+		cells := parse.GetElementsByTag(row, "td")
+		if len(cells) < 2 {
+			continue
+		}
+		size, ok := parse.Size(parse.GetTextContent(cells[len(cells)-1]))
+		path := parse.GetTextContent(cells[0])
+		if !ok || path == "" {
+			continue
+		}
+		files = append(files, &File{Path: path, Size: size})
+	}
+	return files
+}
@@ -0,0 +1,32 @@
+package details
+
+import (
+	"testing"
+
+	"github.com/gar-r/ngore/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFiles(t *testing.T) {
+
+	t.Run("file list", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<table>
+			<tr class="fej"><td>Fájlnév</td><td>Méret</td></tr>
+			<tr><td><span>Movie/movie.mkv</span></td><td>1.5 GiB</td></tr>
+			<tr><td>Movie/Sample/sample.mkv</td><td>20 MiB</td></tr>
+			<tr><td>Movie/movie.nfo</td><td>3,5 KiB</td></tr>
+		</table>`)
+		assert.Equal(t, []*File{
+			{Path: "Movie/movie.mkv", Size: 3 << 29},
+			{Path: "Movie/Sample/sample.mkv", Size: 20 << 20},
+			{Path: "Movie/movie.nfo", Size: 3584},
+		}, ParseFiles(doc))
+	})
+
+	t.Run("no files", func(t *testing.T) {
+		doc := parse.MustParse(t, `<div>nincs</div>`)
+		assert.Empty(t, ParseFiles(doc))
+	})
+
+}
@@ -1,5 +1,7 @@
 package details
 
+import "time"
+
 type Details struct {
 	Type        string   `json:"type"`
 	Title       string   `json:"title"`
@@ -14,4 +16,46 @@ type Details struct {
 	OtherLink   string   `json:"otherLink"`
 	CoverImage  string   `json:"coverImage"`
 	OtherImages []string `json:"otherImages"`
+	Release     Release  `json:"release"`
+	// OtherVersions lists the other releases of the same title, in different quality or language.
+	OtherVersions []*Version `json:"otherVersions"`
+}
+
+// Release holds the facts of the torrent itself, shown for every type.
+type Release struct {
+	// Category is the code of the category used in searches, like "hd_hun".
+	Category     string    `json:"category"`
+	CategoryName string    `json:"categoryName"`
+	Size         int64     `json:"size"`
+	Uploaded     time.Time `json:"uploaded"`
+	Uploader     string    `json:"uploader"`
+	Seeders      int       `json:"seeders"`
+	Leechers     int       `json:"leechers"`
+	Completed    int       `json:"completed"`
+	Freeleech    bool      `json:"freeleech"`
+	NoHitAndRun  bool      `json:"noHitAndRun"`
+}
+
+type Version struct {
+	Id       string `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+	Size     int64  `json:"size"`
+}
+
+type File struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type Comments struct {
+	Comments []*Comment `json:"comments"`
+	Page     int        `json:"page"`
+	HasMore  bool       `json:"hasMore"`
+}
+
+type Comment struct {
+	Author string    `json:"author"`
+	Posted time.Time `json:"posted"`
+	Text   string    `json:"text"`
 }
@@ -0,0 +1,35 @@
+package details
+
+import (
+	"strings"
+
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+// ParseNfo returns the text of the nfo, which the details page loads on demand,
+// with the line breaks and the ascii art preserved.
+func ParseNfo(doc *html.Node) string {
+	root := parse.GetElementByTag(doc, "pre")
+	if root == nil {
+		root = parse.GetElementByTag(doc, "body")
+	}
+	if root == nil {
+		return ""
+	}
+	sb := &strings.Builder{}
+	rawText(root, sb)
+	return strings.Trim(sb.String(), "\r\n")
+}
+
+func rawText(n *html.Node, sb *strings.Builder) {
+	switch {
+	case n.Type == html.TextNode:
+		sb.WriteString(n.Data)
+	case n.Type == html.ElementNode && n.Data == "br":
+		sb.WriteString("\n")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		rawText(c, sb)
+	}
+}
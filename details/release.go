@@ -0,0 +1,65 @@
+package details
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+// exactSizeRegex matches the exact size shown after the rounded one, like "1.69 GiB (1819020964 bájt)"
+var exactSizeRegex = regexp.MustCompile(`\(([\d\s]+) bájt\)`)
+
+func parseRelease(doc *html.Node) Release {
+	r := Release{}
+	div := parse.GetElementByClass(doc, "torrent_reszletek")
+	if div == nil {
+		return r
+	}
+	fields := parse.GetFields(div)
+	text := func(label string) string {
+		if dd := fields.Get(label); dd != nil {
+			return parse.GetTextContent(dd)
+		}
+		return ""
+	}
+	r.Category, r.CategoryName = parseCategory(fields.Get("Típus"))
+	r.Size = parseSize(text("Méret"))
+	r.Uploaded, _ = parse.Time(text("Feltöltve"))
+	r.Uploader = text("Feltöltő")
+	r.Seeders, _ = parse.Int(text("Seederek"))
+	r.Leechers, _ = parse.Int(text("Leecherek"))
+	r.Completed, _ = parse.Int(text("Letöltve"))
+	// the badges are looked for in the box of the torrent, not in the whole page
+	box := div.Parent
+	r.Freeleech = parse.HasBadge(box, parse.FreeleechBadges)
+	r.NoHitAndRun = parse.HasBadge(box, parse.NoHitAndRunBadges)
+	return r
+}
+
+// parseCategory returns the code from the link of the category, like "xvid",
+// and the name of the category, like "Film > SD/EN"
+func parseCategory(dd *html.Node) (string, string) {
+	if dd == nil {
+		return "", ""
+	}
+	code := ""
+	for _, a := range parse.GetElementsByTag(dd, "a") {
+		href, _ := parse.FindAttr(a, "href")
+		if u, err := url.Parse(href); err == nil && u.Query().Has("tipus") {
+			code = u.Query().Get("tipus")
+		}
+	}
+	return code, parse.GetTextContent(dd)
+}
+
+func parseSize(s string) int64 {
+	if m := exactSizeRegex.FindStringSubmatch(s); m != nil {
+		if n, ok := parse.Int(m[1]); ok {
+			return int64(n)
+		}
+	}
+	n, _ := parse.Size(s)
+	return n
+}
@@ -0,0 +1,74 @@
+package details
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gar-r/ngore/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRelease(t *testing.T) {
+
+	t.Run("movie release", func(t *testing.T) {
+		doc := parse.MustParse(t, movieHtml)
+		assert.Equal(t, Release{
+			Category:     "xvid",
+			CategoryName: "Film > SD/EN",
+			Size:         1819020964,
+			Uploaded:     time.Date(2013, 9, 5, 12, 36, 57, 0, parse.Location()),
+			Uploader:     "Anonymous",
+			Seeders:      2,
+			Leechers:     1,
+		}, ParseDetails(doc).Release)
+	})
+
+	t.Run("game release", func(t *testing.T) {
+		doc := parse.MustParse(t, gameHtml)
+		r := ParseDetails(doc).Release
+		assert.Equal(t, "game_rip", r.Category)
+		assert.Equal(t, int64(5531492728), r.Size)
+		assert.Equal(t, 15, r.Seeders)
+	})
+
+	t.Run("every type", func(t *testing.T) {
+		for _, page := range []string{seriesHtml, musicHtml, appHtml, bookHtml} {
+			r := ParseDetails(parse.MustParse(t, page)).Release
+			assert.NotEmpty(t, r.Category)
+			assert.NotZero(t, r.Size)
+			assert.False(t, r.Uploaded.IsZero())
+		}
+	})
+
+	t.Run("badges and completed count", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div class="fobox_tartalom">
+			<img class="freeleech" title="Freeleech">
+			<img src="nohnr.png" alt="Nem hit'n'run-olható">
+			<div class="torrent_reszletek">
+				<div class="dt">Letöltve:</div>
+				<div class="dd">1 234</div>
+				<div class="dt">Méret:</div>
+				<div class="dd">700 MiB</div>
+			</div>
+		</div>`)
+		r := ParseDetails(doc).Release
+		assert.Equal(t, 1234, r.Completed)
+		assert.Equal(t, int64(700<<20), r.Size)
+		assert.True(t, r.Freeleech)
+		assert.True(t, r.NoHitAndRun)
+	})
+
+	t.Run("badges outside the torrent box", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<a title="Freeleech torrentek">menu</a>
+		<div class="fobox_tartalom"><div class="torrent_reszletek"></div></div>`)
+		assert.False(t, ParseDetails(doc).Release.Freeleech)
+	})
+
+	t.Run("no release facts", func(t *testing.T) {
+		doc := parse.MustParse(t, `<div></div>`)
+		assert.Equal(t, Release{}, ParseDetails(doc).Release)
+	})
+
+}
@@ -0,0 +1,70 @@
+package details
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+// otherVersionsRegex matches the script loading the other versions, like
+// other_versions('otherlist', '0120844', '1365642', 1), capturing the imdb id
+var otherVersionsRegex = regexp.MustCompile(`other_versions\(\s*'[^']*'\s*,\s*'([^']*)'`)
+
+// ParseOtherVersionsRef returns the id of the title, which the details page
+// uses to load the other versions on demand.
+func ParseOtherVersionsRef(doc *html.Node) (string, bool) {
+	for _, a := range parse.GetElementsByTag(doc, "a") {
+		onclick, _ := parse.FindAttr(a, "onclick")
+		if m := otherVersionsRegex.FindStringSubmatch(onclick); m != nil && m[1] != "" {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// ParseOtherVersions parses the list of other versions. Every row links to the
+// details of a version, and shows its category and size.
+func ParseOtherVersions(doc *html.Node) []*Version {
+	versions := make([]*Version, 0)
+	rows := parse.GetElementsByClass(doc, "box_torrent")
+	if len(rows) == 0 {
+		rows = parse.GetElementsByTag(doc, "tr")
+	}
+	for _, row := range rows {
+		v := parseVersion(row)
+		if v.Id != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+func parseVersion(row *html.Node) *Version {
+	v := &Version{}
+	for _, a := range parse.GetElementsByTag(row, "a") {
+		href, _ := parse.FindAttr(a, "href")
+		u, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+		q := u.Query()
+		switch {
+		case v.Id == "" && q.Get("action") == "details" && q.Has("id"):
+			v.Id = q.Get("id")
+			v.Title, _ = parse.FindAttr(a, "title")
+			if v.Title == "" {
+				v.Title = parse.GetTextContent(a)
+			}
+		case v.Category == "" && q.Has("tipus"):
+			v.Category = q.Get("tipus")
+		}
+	}
+	size := parse.GetElementByClass(row, "box_meret2")
+	if size == nil {
+		size = row
+	}
+	v.Size, _ = parse.Size(parse.GetTextContent(size))
+	return v
+}
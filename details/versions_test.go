@@ -0,0 +1,66 @@
+package details
+
+import (
+	"testing"
+
+	"github.com/gar-r/ngore/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOtherVersionsRef(t *testing.T) {
+
+	t.Run("movie page", func(t *testing.T) {
+		ref, ok := ParseOtherVersionsRef(parse.MustParse(t, movieHtml))
+		assert.True(t, ok)
+		assert.Equal(t, "0120844", ref)
+	})
+
+	t.Run("no other versions", func(t *testing.T) {
+		_, ok := ParseOtherVersionsRef(parse.MustParse(t, gameHtml))
+		assert.False(t, ok)
+	})
+
+}
+
+func TestParseOtherVersions(t *testing.T) {
+
+	t.Run("torrent boxes", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div class="box_torrent">
+			<div class="box_alap_img"><a href="/torrents.php?tipus=hd_hun"><img alt="HD/HU"></a></div>
+			<div class="torrent_txt">
+				<a href="torrents.php?action=details&id=111" title="Movie 1080p"><nobr>Movie 1080p</nobr></a>
+			</div>
+			<div class="box_meret2">8.5 GiB</div>
+		</div>
+		<div class="box_torrent">
+			<div class="box_alap_img"><a href="/torrents.php?tipus=xvid"><img alt="SD/EN"></a></div>
+			<div class="torrent_txt"><a href="torrents.php?action=details&id=222">Movie SD</a></div>
+			<div class="box_meret2">700 MiB</div>
+		</div>`)
+		assert.Equal(t, []*Version{
+			{Id: "111", Title: "Movie 1080p", Category: "hd_hun", Size: 9126805504},
+			{Id: "222", Title: "Movie SD", Category: "xvid", Size: 700 << 20},
+		}, ParseOtherVersions(doc))
+	})
+
+	t.Run("table rows", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<table>
+			<tr><td>Név</td><td>Méret</td></tr>
+			<tr>
+				<td><a href="torrents.php?tipus=hd">HD/EN</a></td>
+				<td><a href="torrents.php?action=details&id=333">Movie 720p</a></td>
+				<td>4 GiB</td>
+			</tr>
+		</table>`)
+		assert.Equal(t, []*Version{
+			{Id: "333", Title: "Movie 720p", Category: "hd", Size: 4 << 30},
+		}, ParseOtherVersions(doc))
+	})
+
+	t.Run("no versions", func(t *testing.T) {
+		assert.Empty(t, ParseOtherVersions(parse.MustParse(t, `<div>nincs</div>`)))
+	})
+
+}
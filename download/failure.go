@@ -0,0 +1,98 @@
+package download
+
+import (
+	"strings"
+
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+const maxMessageLength = 200
+
+type Reason int
+
+const (
+	Unknown Reason = iota
+	QuotaExceeded
+	HitAndRun
+	InvalidKey
+	Deleted
+)
+
+func (r Reason) String() string {
+	switch r {
+	case QuotaExceeded:
+		return "download quota exceeded"
+	case HitAndRun:
+		return "downloads blocked due to hit and run"
+	case InvalidKey:
+		return "invalid api key"
+	case Deleted:
+		return "torrent deleted"
+	default:
+		return "response is not a torrent file"
+	}
+}
+
+// phrases identify the reason of a failed download in the text of the returned page
+var phrases = []struct {
+	reason  Reason
+	phrases []string
+}{
+	{HitAndRun, []string{"hit&run", "hit'n'run", "hit and run", "hitnrun", "h&r"}},
+	{QuotaExceeded, []string{"letöltési limit", "letöltési korlát", "elérted", "túllépted", "quota"}},
+	{InvalidKey, []string{"hibás kulcs", "érvénytelen kulcs", "hibás passkey", "invalid key", "invalid passkey"}},
+	{Deleted, []string{"nem létezik", "nincs ilyen torrent", "törölve", "törölték", "törölt torrent", "deleted"}},
+}
+
+// ParseFailure explains the page returned instead of a torrent file.
+func ParseFailure(doc *html.Node) (Reason, string) {
+	msg := pageText(doc)
+	lower := strings.ToLower(msg)
+	reason := Unknown
+	for _, p := range phrases {
+		if containsAny(lower, p.phrases) {
+			reason = p.reason
+			break
+		}
+	}
+	return reason, truncate(msg, maxMessageLength)
+}
+
+// messageClasses are the classes of the elements holding the error message
+var messageClasses = []string{"hibauzenet", "hiba"}
+
+// pageText returns the text of the error message, or the text of the whole page
+// when the message can not be found. The message is preferred, as the menu of a
+// full page may mention other reasons, like a link to the hit and run rules.
+func pageText(doc *html.Node) string {
+	for _, class := range messageClasses {
+		if n := parse.GetElementByClass(doc, class); n != nil {
+			if text := parse.GetTextContent(n); text != "" {
+				return text
+			}
+		}
+	}
+	root := parse.GetElementByTag(doc, "body")
+	if root == nil {
+		root = doc
+	}
+	return parse.GetTextContent(root)
+}
+
+func containsAny(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
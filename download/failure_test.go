@@ -0,0 +1,74 @@
+package download
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gar-r/ngore/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFailure(t *testing.T) {
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<html>
+			<head><title>nCore</title><script>var foo = "deleted";</script></head>
+			<body><div class="hibauzenet">Elérted a napi letöltési limitet!</div></body>
+		</html>`)
+		reason, msg := ParseFailure(doc)
+		assert.Equal(t, QuotaExceeded, reason)
+		assert.Equal(t, "Elérted a napi letöltési limitet!", msg)
+	})
+
+	t.Run("message preferred over the layout", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<body>
+			<div id="menu"><a href="/hitnrun.php">Hit&amp;Run</a> | <a href="/rules.php">Szabályzat</a></div>
+			<div class="hibauzenet">Elérted a napi letöltési limitet!</div>
+		</body>`)
+		reason, msg := ParseFailure(doc)
+		assert.Equal(t, QuotaExceeded, reason)
+		assert.Equal(t, "Elérted a napi letöltési limitet!", msg)
+	})
+
+	t.Run("hit and run", func(t *testing.T) {
+		doc := parse.MustParse(t, `<body><p>A letöltés Hit&amp;Run miatt tiltva van.</p></body>`)
+		reason, _ := ParseFailure(doc)
+		assert.Equal(t, HitAndRun, reason)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		doc := parse.MustParse(t, `<body><p>Hibás kulcs!</p></body>`)
+		reason, _ := ParseFailure(doc)
+		assert.Equal(t, InvalidKey, reason)
+	})
+
+	t.Run("torrent deleted", func(t *testing.T) {
+		doc := parse.MustParse(t, `<body><p>A kért torrent nem létezik.</p></body>`)
+		reason, _ := ParseFailure(doc)
+		assert.Equal(t, Deleted, reason)
+	})
+
+	t.Run("unknown page", func(t *testing.T) {
+		doc := parse.MustParse(t, `<body><p>Karbantartás</p></body>`)
+		reason, msg := ParseFailure(doc)
+		assert.Equal(t, Unknown, reason)
+		assert.Equal(t, "Karbantartás", msg)
+	})
+
+	t.Run("long message truncated", func(t *testing.T) {
+		doc := parse.MustParse(t, `<body>`+strings.Repeat("á", 300)+`</body>`)
+		_, msg := ParseFailure(doc)
+		assert.Equal(t, strings.Repeat("á", maxMessageLength)+"...", msg)
+	})
+
+}
+
+func TestReason_String(t *testing.T) {
+	assert.Equal(t, "download quota exceeded", QuotaExceeded.String())
+	assert.Equal(t, "downloads blocked due to hit and run", HitAndRun.String())
+	assert.Equal(t, "invalid api key", InvalidKey.String())
+	assert.Equal(t, "torrent deleted", Deleted.String())
+	assert.Equal(t, "response is not a torrent file", Unknown.String())
+}
@@ -0,0 +1,29 @@
+package download
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+func ParseHeader(res *http.Response) *Info {
+	return &Info{
+		FileName:      parseFileName(res.Header),
+		ContentLength: res.ContentLength,
+		ContentType:   res.Header.Get("Content-Type"),
+	}
+}
+
+func parseFileName(h http.Header) string {
+	_, params, err := mime.ParseMediaType(h.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	name := strings.ReplaceAll(params["filename"], "\\", "/")
+	name = filepath.Base(filepath.FromSlash(name))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name
+}
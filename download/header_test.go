@@ -0,0 +1,54 @@
+package download
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHeader(t *testing.T) {
+
+	response := func(disposition string) *http.Response {
+		res := &http.Response{
+			Header:        http.Header{},
+			ContentLength: 1234,
+		}
+		res.Header.Set("Content-Type", "application/x-bittorrent")
+		if disposition != "" {
+			res.Header.Set("Content-Disposition", disposition)
+		}
+		return res
+	}
+
+	t.Run("download info", func(t *testing.T) {
+		info := ParseHeader(response(`attachment; filename="Star.Trek.torrent"`))
+		expected := &Info{
+			FileName:      "Star.Trek.torrent",
+			ContentLength: 1234,
+			ContentType:   "application/x-bittorrent",
+		}
+		assert.Equal(t, expected, info)
+	})
+
+	t.Run("encoded file name", func(t *testing.T) {
+		info := ParseHeader(response(`attachment; filename*=UTF-8''%C5%B0rl%C3%A1zad%C3%A1s.torrent`))
+		assert.Equal(t, "Űrlázadás.torrent", info.FileName)
+	})
+
+	t.Run("path stripped from file name", func(t *testing.T) {
+		assert.Equal(t, "passwd", ParseHeader(response(`attachment; filename="../../etc/passwd"`)).FileName)
+		assert.Equal(t, "evil.torrent", ParseHeader(response(`attachment; filename="..\\evil.torrent"`)).FileName)
+		assert.Equal(t, "", ParseHeader(response(`attachment; filename=".."`)).FileName)
+		assert.Equal(t, "", ParseHeader(response(`attachment; filename="foo/.."`)).FileName)
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		assert.Equal(t, "", ParseHeader(response("")).FileName)
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		assert.Equal(t, "", ParseHeader(response(`attachment; filename=`)).FileName)
+	})
+
+}
@@ -0,0 +1,8 @@
+package download
+
+type Info struct {
+	FileName      string `json:"fileName"`
+	ContentLength int64  `json:"contentLength"`
+	ContentType   string `json:"contentType"`
+	Path          string `json:"path,omitempty"`
+}
@@ -0,0 +1,69 @@
+package ngore
+
+import (
+	"fmt"
+
+	"github.com/gar-r/ngore/download"
+	"github.com/gar-r/ngore/internal"
+)
+
+var (
+	// ErrUserNotLoggedIn is returned when the site redirects to the login page.
+	ErrUserNotLoggedIn = internal.ErrUserNotLoggedIn
+	// ErrSessionExpired is returned when a restored session is no longer accepted.
+	// It also matches ErrUserNotLoggedIn.
+	ErrSessionExpired = internal.ErrSessionExpired
+	// ErrKeyOnly is returned by the operations which need a session, when the api
+	// was created with a key only. It also matches ErrUserNotLoggedIn.
+	ErrKeyOnly = internal.ErrKeyOnly
+	// ErrApiKeyEmpty is returned when downloading before logging in.
+	ErrApiKeyEmpty             = internal.ErrApiKeyEmpty
+	ErrInvalidBaseUrl          = internal.ErrSessionInvalidBaseUrl
+	ErrMissingCredentials      = internal.ErrLoginMissingCredentials
+	ErrInvalidCredentials      = internal.ErrLoginInvalidCredentials
+	ErrUnexpectedLoginResponse = internal.ErrLoginUnexpectedResponse
+	ErrTwoFactorCodeRequired   = internal.ErrLoginTwoFactorCodeRequired
+	ErrKeyMissing              = internal.ErrLoginKeyMissing
+	ErrKeyParse                = internal.ErrLoginKeyParse
+	// ErrBookmarkFailed is returned when adding or removing a bookmark is answered with a page.
+	ErrBookmarkFailed = internal.ErrBookmarkFailed
+)
+
+// StatusError is returned when the site answers with an unexpected status code.
+type StatusError struct {
+	Op         string
+	Url        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed: unexpected response code: %d", e.Op, e.StatusCode)
+}
+
+// RequestError wraps the transport error of a failed request,
+// including the context error when the request was cancelled.
+type RequestError struct {
+	Op  string
+	Url string
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Op, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// DownloadError is returned when the site answers a download with a page,
+// instead of a torrent file. The Reason is parsed from the text of the page.
+type DownloadError struct {
+	Id      string
+	Reason  download.Reason
+	Message string
+}
+
+func (e *DownloadError) Error() string {
+	return fmt.Sprintf("download failed: %s", e.Reason)
+}
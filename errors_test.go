@@ -0,0 +1,82 @@
+package ngore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gar-r/ngore/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusError(t *testing.T) {
+
+	t.Run("error message", func(t *testing.T) {
+		err := &StatusError{Op: "search", Url: "https://example.com", StatusCode: http.StatusBadGateway}
+		assert.Equal(t, "search failed: unexpected response code: 502", err.Error())
+	})
+
+	t.Run("returned for unexpected status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		_, err := apiWithMockClient(server).Search(&search.Params{})
+		var statusErr *StatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, "search", statusErr.Op)
+		assert.Equal(t, server.URL+"/torrents.php", statusErr.Url)
+		assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	})
+
+	t.Run("api key redacted", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		a := apiWithMockClient(server)
+		a.(*api).key = "secret"
+		_, err := a.Download("1")
+		var statusErr *StatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.NotContains(t, statusErr.Url, "secret")
+	})
+
+}
+
+func TestRequestError(t *testing.T) {
+
+	t.Run("unwrap", func(t *testing.T) {
+		cause := errors.New("test error")
+		err := &RequestError{Op: "search", Url: "https://example.com", Err: cause}
+		assert.ErrorIs(t, err, cause)
+		assert.Equal(t, "search failed: test error", err.Error())
+	})
+
+	t.Run("returned for transport error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		a := apiWithMockClient(server)
+		server.Close()
+		_, err := a.Activity()
+		var requestErr *RequestError
+		assert.ErrorAs(t, err, &requestErr)
+		assert.Equal(t, "fetching activity", requestErr.Op)
+	})
+
+	t.Run("wraps context error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Default("https://example.com").ActivityContext(ctx)
+		var requestErr *RequestError
+		assert.ErrorAs(t, err, &requestErr)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+}
+
+func TestErrSessionExpired(t *testing.T) {
+	assert.ErrorIs(t, ErrSessionExpired, ErrUserNotLoggedIn)
+	assert.NotErrorIs(t, ErrUserNotLoggedIn, ErrSessionExpired)
+}
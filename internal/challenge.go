@@ -0,0 +1,18 @@
+package internal
+
+import (
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+// IsTwoFactorChallenge reports whether the login page asks for
+// a two-factor authentication code.
+func IsTwoFactorChallenge(doc *html.Node) bool {
+	for _, input := range parse.GetElementsByTag(doc, "input") {
+		name, ok := parse.FindAttr(input, "name")
+		if ok && name == FieldTwoFactor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gar-r/ngore/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTwoFactorChallenge(t *testing.T) {
+
+	t.Run("two-factor code input", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<form method="post" action="login.php">
+			<input type="hidden" name="nev" value="user">
+			<input type="text" name="2factor">
+		</form>`)
+		assert.True(t, IsTwoFactorChallenge(doc))
+	})
+
+	t.Run("login form", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<form method="post" action="login.php">
+			<input type="text" name="nev">
+			<input type="password" name="pass">
+		</form>`)
+		assert.False(t, IsTwoFactorChallenge(doc))
+	})
+
+}
@@ -4,21 +4,28 @@ const LocationLogin = "login.php"
 const LocationIndex = "index.php"
 const LocationLoginProblem = "problema"
 
+const FieldTwoFactor = "2factor"
+
 const UrlLogin = "/login.php"
 const UrlIndex = "/index.php"
 const UrlTorrents = "/torrents.php"
 const UrlActivity = "/hitnrun.php"
 const UrlRecommended = "/recommended.php"
+const UrlRss = "/rss.php"
+const UrlBookmarks = "/bookmarks.php"
+const UrlProfile = "/profile.php"
+const UrlAjax = "/ajax.php"
 
-const ErrUserNotLoggedIn = "user is not logged in"
-const ErrApiKeyEmpty = "api key is empty"
-const ErrLoginMissingCredentials = "login failed: user name or password is empty"
-const ErrLoginInvalidCredentials = "login failed: invalid BasicAuth"
-const ErrLoginUnexpectedResponse = "login failed: unexpected response"
-const ErrLoginUnableToFetchIndex = "login failed: unable to fetch index page"
-const ErrLoginKeyMissing = "login failed: unable to find login key in response"
-const ErrLoginKeyParse = "login failed: login key cannot be parsed"
-const ErrSearchUnexpectedResponseCode = "search failed: unexpected response code: %d"
-const ErrActivityUnexpectedResponseCode = "fetching activity failed: unexpected response code: %d"
-const ErrDownloadUnexpectedResponseCode = "download failed: unexpected response code: %d"
-const ErrDetailsUnexpectedResponseCode = "failed to fetch details: unexpected response code: %d"
+const OpLogin = "login"
+const OpSearch = "search"
+const OpActivity = "fetching activity"
+const OpRecommendations = "fetching recommendations"
+const OpDetails = "fetching details"
+const OpDownload = "download"
+const OpRss = "fetching rss feed"
+const OpBookmarks = "fetching bookmarks"
+const OpBookmark = "updating bookmarks"
+const OpProfile = "fetching profile"
+const OpFiles = "fetching file list"
+const OpNfo = "fetching nfo"
+const OpComments = "fetching comments"
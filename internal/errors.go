@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUserNotLoggedIn = errors.New("user is not logged in")
+var ErrApiKeyEmpty = errors.New("api key is empty")
+var ErrSessionExpired = fmt.Errorf("session expired: %w", ErrUserNotLoggedIn)
+var ErrKeyOnly = fmt.Errorf("operation requires a login, the api only has a key: %w", ErrUserNotLoggedIn)
+var ErrSessionInvalidBaseUrl = errors.New("session failed: invalid base url")
+var ErrLoginMissingCredentials = errors.New("login failed: user name or password is empty")
+var ErrLoginInvalidCredentials = errors.New("login failed: invalid credentials")
+var ErrLoginUnexpectedResponse = errors.New("login failed: unexpected response")
+var ErrLoginTwoFactorCodeRequired = errors.New("login failed: two-factor authentication code required")
+var ErrLoginKeyMissing = errors.New("login failed: unable to find login key in response")
+var ErrLoginKeyParse = errors.New("login failed: login key cannot be parsed")
+var ErrBookmarkFailed = errors.New("bookmark update failed: unexpected response")
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"net/url"
+	"slices"
 	"strconv"
 
 	"github.com/gar-r/ngore/login"
@@ -15,15 +16,22 @@ func AuthForm(a login.Auth) url.Values {
 	}
 }
 
+func TwoFactorForm(a login.Auth, code string) url.Values {
+	val := AuthForm(a)
+	val.Set(FieldTwoFactor, code)
+	return val
+}
+
 func SearchForm(s *search.Params) url.Values {
-	if s.Page < 1 {
-		s.Page = 1
-	}
 	val := url.Values{}
 	val.Set("mire", s.SearchPhrase)
 	val.Set("miben", s.Field.String())
-	val.Set("tipus", s.Category.String())
-	val.Set("oldal", strconv.Itoa(s.Page))
+	if len(s.Categories) > 0 {
+		setCategories(val, s.Categories)
+	} else {
+		val.Set("tipus", s.Category.String())
+	}
+	val.Set("oldal", strconv.Itoa(max(s.Page, 1)))
 
 	// do not apply sorting by name when we are searching by description
 	// this is due to a bug in the website
@@ -34,3 +42,32 @@ func SearchForm(s *search.Params) url.Values {
 	val.Set("hogyan", s.SortMode.String())
 	return val
 }
+
+// RssQuery selects the feed of the given categories, or the whole feed without any.
+func RssQuery(key string, categories []search.Category) url.Values {
+	val := url.Values{}
+	val.Set("key", key)
+	setCategories(val, categories)
+	return val
+}
+
+// setCategories selects a single category directly, or several of them
+// with the multi-type fields of the form. Duplicates are sent only once.
+func setCategories(val url.Values, categories []search.Category) {
+	unique := make([]search.Category, 0, len(categories))
+	for _, c := range categories {
+		if !slices.Contains(unique, c) {
+			unique = append(unique, c)
+		}
+	}
+	switch categories = unique; len(categories) {
+	case 0:
+	case 1:
+		val.Set("tipus", categories[0].String())
+	default:
+		val.Set("tipus", "kivalasztottak_kozott")
+		for _, c := range categories {
+			val.Add("kivalasztott_tipus[]", c.String())
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gar-r/ngore/login"
 	"github.com/gar-r/ngore/search"
 	"github.com/stretchr/testify/assert"
+	"slices"
 	"testing"
 )
 
@@ -17,6 +18,17 @@ func TestAuthForm(t *testing.T) {
 	assert.Equal(t, "pass", val.Get("pass"))
 }
 
+func TestTwoFactorForm(t *testing.T) {
+	a := &login.BasicAuth{
+		UserName: "user",
+		Password: "pass",
+	}
+	val := TwoFactorForm(a, "123456")
+	assert.Equal(t, "user", val.Get("nev"))
+	assert.Equal(t, "pass", val.Get("pass"))
+	assert.Equal(t, "123456", val.Get("2factor"))
+}
+
 func TestSearchForm(t *testing.T) {
 
 	t.Run("page number indexing", func(t *testing.T) {
@@ -25,6 +37,7 @@ func TestSearchForm(t *testing.T) {
 		}
 		f := SearchForm(s)
 		assert.Equal(t, []string{"1"}, f["oldal"])
+		assert.Equal(t, 0, s.Page)
 	})
 
 	t.Run("sort params", func(t *testing.T) {
@@ -49,4 +62,49 @@ func TestSearchForm(t *testing.T) {
 		assert.False(t, f.Has("hogyan"))
 	})
 
+	t.Run("single category", func(t *testing.T) {
+		f := SearchForm(&search.Params{Category: search.MovieHdEn})
+		assert.Equal(t, "hd", f.Get("tipus"))
+		assert.False(t, f.Has("kivalasztott_tipus[]"))
+	})
+
+	t.Run("multiple categories", func(t *testing.T) {
+		f := SearchForm(&search.Params{
+			Category:   search.MovieSdHu,
+			Categories: []search.Category{search.MovieHdHu, search.MovieHdEn, search.MovieHdHu},
+		})
+		assert.Equal(t, "kivalasztottak_kozott", f.Get("tipus"))
+		assert.Equal(t, []string{"hd_hun", "hd"}, f["kivalasztott_tipus[]"])
+	})
+
+	t.Run("category groups", func(t *testing.T) {
+		f := SearchForm(&search.Params{
+			Categories: slices.Concat(search.AllMovies, search.AllHu),
+		})
+		assert.Equal(t, "kivalasztottak_kozott", f.Get("tipus"))
+		assert.Len(t, f["kivalasztott_tipus[]"], 14)
+		assert.Contains(t, f["kivalasztott_tipus[]"], "ebook_hun")
+	})
+
+}
+
+func TestRssQuery(t *testing.T) {
+
+	t.Run("whole feed", func(t *testing.T) {
+		val := RssQuery("abc", nil)
+		assert.Equal(t, "abc", val.Get("key"))
+		assert.False(t, val.Has("tipus"))
+	})
+
+	t.Run("single category", func(t *testing.T) {
+		val := RssQuery("abc", []search.Category{search.MovieHdHu})
+		assert.Equal(t, "hd_hun", val.Get("tipus"))
+	})
+
+	t.Run("several categories", func(t *testing.T) {
+		val := RssQuery("abc", []search.Category{search.MovieHdHu, search.SeriesHdHu})
+		assert.Equal(t, "kivalasztottak_kozott", val.Get("tipus"))
+		assert.Equal(t, []string{"hd_hun", "hdser_hun"}, val["kivalasztott_tipus[]"])
+	})
+
 }
@@ -1,9 +1,9 @@
 package internal
 
 import (
-	"errors"
 	"github.com/gar-r/ngore/parse"
 	"golang.org/x/net/html"
+	"net/url"
 	"strings"
 )
 
@@ -15,7 +15,7 @@ func ExtractKey(doc *html.Node) (string, error) {
 			return extractKey(href)
 		}
 	}
-	return "", errors.New(ErrLoginKeyMissing)
+	return "", ErrLoginKeyMissing
 }
 
 func isRssRef(s string) bool {
@@ -25,7 +25,20 @@ func isRssRef(s string) bool {
 func extractKey(s string) (string, error) {
 	parts := strings.Split(s, "=")
 	if len(parts) != 2 {
-		return "", errors.New(ErrLoginKeyParse)
+		return "", ErrLoginKeyParse
 	}
 	return parts[1], nil
 }
+
+// RedactKey returns the url with the value of the key parameter hidden,
+// so that urls can be reported in errors without leaking the api key.
+func RedactKey(u *url.URL) string {
+	q := u.Query()
+	if !q.Has("key") {
+		return u.String()
+	}
+	q.Set("key", "REDACTED")
+	r := *u
+	r.RawQuery = q.Encode()
+	return r.String()
+}
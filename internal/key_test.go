@@ -3,6 +3,7 @@ package internal
 import (
 	"github.com/gar-r/ngore/parse"
 	"github.com/stretchr/testify/assert"
+	"net/url"
 	"testing"
 )
 
@@ -34,3 +35,17 @@ func TestExtractKey(t *testing.T) {
 	})
 
 }
+
+func TestRedactKey(t *testing.T) {
+
+	t.Run("key hidden", func(t *testing.T) {
+		u, _ := url.Parse("https://example.com/torrents.php?action=download&id=1&key=secret_key")
+		assert.Equal(t, "https://example.com/torrents.php?action=download&id=1&key=REDACTED", RedactKey(u))
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		u, _ := url.Parse("https://example.com/torrents.php?action=details&id=1")
+		assert.Equal(t, "https://example.com/torrents.php?action=details&id=1", RedactKey(u))
+	})
+
+}
@@ -28,3 +28,10 @@ func IsRedirectToLocation(res *http.Response, location string) bool {
 func IsRedirect(res *http.Response) bool {
 	return res.StatusCode == http.StatusFound
 }
+
+// IsPage reports whether the beginning of the body is a whole html page. Ajax actions
+// answer with a short fragment, so a page means an error, or an unknown action.
+func IsPage(body []byte) bool {
+	s := strings.ToLower(string(body))
+	return strings.Contains(s, "<html") || strings.Contains(s, "<body") || strings.Contains(s, "hibauzenet")
+}
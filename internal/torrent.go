@@ -0,0 +1,7 @@
+package internal
+
+// LooksLikeTorrent reports whether the data starts like a torrent file,
+// for when only the beginning of a download is available.
+func LooksLikeTorrent(prefix []byte) bool {
+	return len(prefix) > 0 && prefix[0] == 'd'
+}
@@ -0,0 +1,13 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLooksLikeTorrent(t *testing.T) {
+	assert.True(t, LooksLikeTorrent([]byte("d8:announce")))
+	assert.False(t, LooksLikeTorrent([]byte("<!DOCTYPE html>")))
+	assert.False(t, LooksLikeTorrent(nil))
+}
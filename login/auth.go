@@ -5,9 +5,17 @@ type Auth interface {
 	Pass() string
 }
 
+// TwoFactorAuth is implemented by credentials, which can provide a code
+// when the site asks for one during login.
+type TwoFactorAuth interface {
+	Auth
+	CodeProvider() CodeProvider
+}
+
 type BasicAuth struct {
-	UserName string
-	Password string
+	UserName  string
+	Password  string
+	TwoFactor CodeProvider
 }
 
 func (b *BasicAuth) User() string {
@@ -17,3 +25,7 @@ func (b *BasicAuth) User() string {
 func (b *BasicAuth) Pass() string {
 	return b.Password
 }
+
+func (b *BasicAuth) CodeProvider() CodeProvider {
+	return b.TwoFactor
+}
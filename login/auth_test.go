@@ -18,3 +18,10 @@ func TestBasicAuth_Pass(t *testing.T) {
 	}
 	assert.Equal(t, "pass", b.Pass())
 }
+
+func TestBasicAuth_CodeProvider(t *testing.T) {
+	b := &BasicAuth{
+		TwoFactor: StaticCode("123456"),
+	}
+	assert.Equal(t, StaticCode("123456"), b.CodeProvider())
+}
@@ -0,0 +1,84 @@
+package login
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultTotpDigits = 6
+const defaultTotpPeriod = 30 * time.Second
+
+type CodeProvider interface {
+	Code() (string, error)
+}
+
+// StaticCode provides the same code every time, which is useful
+// for a single login with a code acquired out of band.
+type StaticCode string
+
+func (c StaticCode) Code() (string, error) {
+	return string(c), nil
+}
+
+// CodeFunc provides codes from a callback, e.g. by prompting the user.
+type CodeFunc func() (string, error)
+
+func (f CodeFunc) Code() (string, error) {
+	return f()
+}
+
+// Totp generates time based one-time passwords (RFC 6238) from the
+// base32 encoded secret shown when two-factor authentication was set up.
+type Totp struct {
+	Secret string
+	Digits int
+	Period time.Duration
+}
+
+func (t *Totp) Code() (string, error) {
+	return t.CodeAt(time.Now())
+}
+
+func (t *Totp) CodeAt(now time.Time) (string, error) {
+	key, err := decodeSecret(t.Secret)
+	if err != nil {
+		return "", err
+	}
+	digits := t.Digits
+	if digits <= 0 {
+		digits = defaultTotpDigits
+	}
+	period := t.Period
+	if period <= 0 {
+		period = defaultTotpPeriod
+	}
+	// counted in nanoseconds, so periods that are not whole seconds work too
+	counter := uint64(now.UnixNano() / int64(period))
+	return hotp(key, counter, digits), nil
+}
+
+func hotp(key []byte, counter uint64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	s = strings.TrimRight(s, "=")
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+}
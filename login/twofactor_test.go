@@ -0,0 +1,94 @@
+package login
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticCode_Code(t *testing.T) {
+	code, err := StaticCode("123456").Code()
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", code)
+}
+
+func TestCodeFunc_Code(t *testing.T) {
+
+	t.Run("code from callback", func(t *testing.T) {
+		f := CodeFunc(func() (string, error) {
+			return "654321", nil
+		})
+		code, err := f.Code()
+		assert.NoError(t, err)
+		assert.Equal(t, "654321", code)
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		f := CodeFunc(func() (string, error) {
+			return "", errors.New("test error")
+		})
+		_, err := f.Code()
+		assert.Error(t, err)
+	})
+
+}
+
+func TestTotp_CodeAt(t *testing.T) {
+
+	// test vectors from RFC 6238, using the SHA1 secret "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	t.Run("rfc test vectors", func(t *testing.T) {
+		totp := &Totp{Secret: secret, Digits: 8}
+		vectors := map[int64]string{
+			59:         "94287082",
+			1111111109: "07081804",
+			1111111111: "14050471",
+			1234567890: "89005924",
+			2000000000: "69279037",
+		}
+		for ts, expected := range vectors {
+			code, err := totp.CodeAt(time.Unix(ts, 0))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, code)
+		}
+	})
+
+	t.Run("default digits", func(t *testing.T) {
+		totp := &Totp{Secret: secret}
+		code, err := totp.CodeAt(time.Unix(59, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, "287082", code)
+	})
+
+	t.Run("secret formatting ignored", func(t *testing.T) {
+		totp := &Totp{Secret: "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+		code, err := totp.CodeAt(time.Unix(59, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, "287082", code)
+	})
+
+	t.Run("period under a second", func(t *testing.T) {
+		// the counter is 1 both at 59s with the default period, and at 0.7s with a period of 500ms
+		totp := &Totp{Secret: secret, Period: 500 * time.Millisecond}
+		code, err := totp.CodeAt(time.Unix(0, int64(700*time.Millisecond)))
+		assert.NoError(t, err)
+		assert.Equal(t, "287082", code)
+	})
+
+	t.Run("fractional period", func(t *testing.T) {
+		totp := &Totp{Secret: secret, Period: 1500 * time.Millisecond}
+		code, err := totp.CodeAt(time.Unix(2, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, "287082", code)
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		totp := &Totp{Secret: "not base32!"}
+		_, err := totp.CodeAt(time.Unix(59, 0))
+		assert.Error(t, err)
+	})
+
+}
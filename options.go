@@ -0,0 +1,175 @@
+package ngore
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gar-r/ngore/internal"
+	"github.com/gar-r/ngore/ratelimit"
+	"github.com/gar-r/ngore/retry"
+	"github.com/gar-r/ngore/session"
+)
+
+// Operation names accepted by WithOperationTimeout, also reported
+// in the Op field of StatusError and RequestError.
+const (
+	OpLogin           = internal.OpLogin
+	OpSearch          = internal.OpSearch
+	OpActivity        = internal.OpActivity
+	OpRecommendations = internal.OpRecommendations
+	OpDetails         = internal.OpDetails
+	OpDownload        = internal.OpDownload
+	OpRss             = internal.OpRss
+	OpBookmarks       = internal.OpBookmarks
+	OpBookmark        = internal.OpBookmark
+	OpProfile         = internal.OpProfile
+	OpFiles           = internal.OpFiles
+	OpNfo             = internal.OpNfo
+	OpComments        = internal.OpComments
+)
+
+var ErrProxyUnsupported = errors.New("proxy can only be set on an *http.Transport")
+
+type Option func(o *options)
+
+type options struct {
+	client          *http.Client
+	jar             http.CookieJar
+	timeout         time.Duration
+	timeouts        map[string]time.Duration
+	header          http.Header
+	proxy           *url.URL
+	logger          *slog.Logger
+	limiter         *ratelimit.Limiter
+	downloadLimiter *ratelimit.Limiter
+	retryPolicy     *retry.Policy
+	session         *session.Session
+	key             string
+}
+
+// WithClient sets the client used to send requests. The client is copied,
+// so the settings changed by the api do not affect the caller's client.
+func WithClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithCookieJar sets the cookie jar holding the session cookies.
+// By default the jar of the client is used, or a new one if it has none.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(o *options) {
+		o.jar = jar
+	}
+}
+
+// WithTimeout limits the duration of every api call, including retries.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithOperationTimeout limits the duration of the given operation,
+// overriding WithTimeout for that operation.
+func WithOperationTimeout(op string, timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeouts[op] = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// WithHeader adds a header to every request sent by the api.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithProxy routes the requests through the given proxy. The transport
+// of the client is cloned, so it must be an *http.Transport, or nil.
+func WithProxy(proxy *url.URL) Option {
+	return func(o *options) {
+		o.proxy = proxy
+	}
+}
+
+// WithLogger enables logging of the requests sent by the api.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+func WithRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+func WithDownloadRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(o *options) {
+		o.downloadLimiter = limiter
+	}
+}
+
+func WithRetryPolicy(policy *retry.Policy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithSession restores a session exported by Api.Session.
+func WithSession(s *session.Session) Option {
+	return func(o *options) {
+		o.session = s
+	}
+}
+
+// WithKey sets the api key, which is enough to download torrents and read the rss feed,
+// without logging in. Operations which need a session fail with ErrKeyOnly, until a login.
+func WithKey(key string) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+func (o *options) httpClient() (*http.Client, error) {
+	client := &http.Client{}
+	if o.client != nil {
+		*client = *o.client
+	}
+	switch {
+	case o.jar != nil:
+		client.Jar = o.jar
+	case client.Jar == nil:
+		initCookieJar(client)
+	}
+	disableRedirect(client)
+	if o.proxy != nil {
+		transport, err := cloneTransport(client.Transport)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(o.proxy)
+		client.Transport = transport
+	}
+	return client, nil
+}
+
+func cloneTransport(rt http.RoundTripper) (*http.Transport, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, ErrProxyUnsupported
+	}
+	return transport.Clone(), nil
+}
@@ -0,0 +1,181 @@
+package ngore
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gar-r/ngore/ratelimit"
+	"github.com/gar-r/ngore/retry"
+	"github.com/gar-r/ngore/session"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewWithOptions(t *testing.T) {
+
+	t.Run("defaults", func(t *testing.T) {
+		a, err := NewWithOptions("baseUrl")
+		assert.NoError(t, err)
+		ng := a.(*api)
+		assert.NotNil(t, ng.client.Jar)
+		assert.NotNil(t, ng.client.CheckRedirect)
+		assert.NotNil(t, ng.logger)
+	})
+
+	t.Run("client copied", func(t *testing.T) {
+		client := &http.Client{Timeout: time.Minute}
+		a, err := NewWithOptions("baseUrl", WithClient(client))
+		assert.NoError(t, err)
+		ng := a.(*api)
+		assert.NotSame(t, client, ng.client)
+		assert.Equal(t, time.Minute, ng.client.Timeout)
+		assert.Nil(t, client.CheckRedirect)
+		assert.Nil(t, client.Jar)
+	})
+
+	t.Run("client cookie jar kept", func(t *testing.T) {
+		jar, _ := cookiejar.New(nil)
+		a, err := NewWithOptions("baseUrl", WithClient(&http.Client{Jar: jar}))
+		assert.NoError(t, err)
+		assert.Same(t, jar, a.(*api).client.Jar)
+	})
+
+	t.Run("custom cookie jar", func(t *testing.T) {
+		jar, _ := cookiejar.New(nil)
+		a, err := NewWithOptions("baseUrl", WithCookieJar(jar))
+		assert.NoError(t, err)
+		assert.Same(t, jar, a.(*api).client.Jar)
+	})
+
+	t.Run("rate limit and retry policy", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.Config{})
+		downloadLimiter := ratelimit.New(ratelimit.Config{})
+		policy := retry.Default()
+		a, err := NewWithOptions("baseUrl",
+			WithRateLimit(limiter),
+			WithDownloadRateLimit(downloadLimiter),
+			WithRetryPolicy(policy))
+		assert.NoError(t, err)
+		ng := a.(*api)
+		assert.Same(t, limiter, ng.limiter)
+		assert.Same(t, downloadLimiter, ng.downloadLimiter)
+		assert.Same(t, policy, ng.retryPolicy)
+	})
+
+	t.Run("session restored", func(t *testing.T) {
+		a, err := NewWithOptions("https://example.com", WithSession(&session.Session{Key: "abc123"}))
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", a.(*api).key)
+	})
+
+	t.Run("invalid session base url", func(t *testing.T) {
+		_, err := NewWithOptions("://foo", WithSession(&session.Session{}))
+		assert.ErrorIs(t, err, ErrInvalidBaseUrl)
+	})
+
+}
+
+func TestWithHeader(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer server.Close()
+	a, err := NewWithOptions(server.URL,
+		WithClient(server.Client()),
+		WithUserAgent("ngore-test"),
+		WithHeader("Accept-Language", "hu"))
+	assert.NoError(t, err)
+	_, _ = a.Activity()
+	assert.Equal(t, "ngore-test", header.Get("User-Agent"))
+	assert.Equal(t, "hu", header.Get("Accept-Language"))
+}
+
+func TestWithTimeout(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	t.Run("timeout for every operation", func(t *testing.T) {
+		a, err := NewWithOptions(server.URL, WithClient(server.Client()), WithTimeout(10*time.Millisecond))
+		assert.NoError(t, err)
+		_, err = a.Activity()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("timeout for a single operation", func(t *testing.T) {
+		a, err := NewWithOptions(server.URL,
+			WithClient(server.Client()),
+			WithTimeout(time.Hour),
+			WithOperationTimeout(OpDetails, 10*time.Millisecond))
+		assert.NoError(t, err)
+		_, err = a.Details("1")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+}
+
+func TestWithProxy(t *testing.T) {
+
+	proxy, _ := url.Parse("http://proxy.example.com:8080")
+
+	t.Run("proxy set on a cloned transport", func(t *testing.T) {
+		transport := &http.Transport{}
+		client := &http.Client{Transport: transport}
+		a, err := NewWithOptions("baseUrl", WithClient(client), WithProxy(proxy))
+		assert.NoError(t, err)
+		cloned := a.(*api).client.Transport.(*http.Transport)
+		assert.NotSame(t, transport, cloned)
+		assert.Nil(t, transport.Proxy)
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		u, err := cloned.Proxy(req)
+		assert.NoError(t, err)
+		assert.Equal(t, proxy, u)
+	})
+
+	t.Run("default transport", func(t *testing.T) {
+		a, err := NewWithOptions("baseUrl", WithProxy(proxy))
+		assert.NoError(t, err)
+		assert.IsType(t, &http.Transport{}, a.(*api).client.Transport)
+	})
+
+	t.Run("unsupported transport", func(t *testing.T) {
+		client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, nil
+		})}
+		_, err := NewWithOptions("baseUrl", WithClient(client), WithProxy(proxy))
+		assert.ErrorIs(t, err, ErrProxyUnsupported)
+	})
+
+}
+
+func TestWithLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	a, err := NewWithOptions(server.URL, WithClient(server.Client()), WithLogger(logger))
+	assert.NoError(t, err)
+	a.(*api).key = "secret"
+	_, _ = a.Download("1")
+	assert.Contains(t, buf.String(), "op=download")
+	assert.Contains(t, buf.String(), "status=200")
+	assert.NotContains(t, buf.String(), "secret")
+}
@@ -0,0 +1,35 @@
+package parse
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Badges identify the flags of a torrent by the class, title or alt text of their icons.
+var (
+	FreeleechBadges   = []string{"freeleech"}
+	NoHitAndRunBadges = []string{"nohnr", "no_hnr", "nem hit'n'run", "hit'n'run mentes"}
+)
+
+// HasBadge reports whether the node or any of its descendants has a class, title or alt
+// attribute containing one of the badges, ignoring case.
+func HasBadge(n *html.Node, badges []string) bool {
+	return traverse(n, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return false
+		}
+		for _, attr := range n.Attr {
+			if attr.Key != "class" && attr.Key != "title" && attr.Key != "alt" {
+				continue
+			}
+			v := strings.ToLower(attr.Val)
+			for _, b := range badges {
+				if strings.Contains(v, b) {
+					return true
+				}
+			}
+		}
+		return false
+	}) != nil
+}
@@ -0,0 +1,22 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasBadge(t *testing.T) {
+
+	t.Run("attributes matched", func(t *testing.T) {
+		doc := MustParse(t, `<div><img class="x" alt="FreeLeech"><span title="Hit'n'run mentes"></span></div>`)
+		assert.True(t, HasBadge(doc, FreeleechBadges))
+		assert.True(t, HasBadge(doc, NoHitAndRunBadges))
+	})
+
+	t.Run("text not matched", func(t *testing.T) {
+		doc := MustParse(t, `<div><a href="/freeleech">freeleech</a></div>`)
+		assert.False(t, HasBadge(doc, FreeleechBadges))
+	})
+
+}
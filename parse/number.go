@@ -0,0 +1,75 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Int parses the first whole number in the text, like the 3 in "3 (1 elküldve)".
+// Groups of three digits may be separated by a space, a non-breaking space or a dot.
+// A number with a fraction, like "1.5", is not a whole number.
+func Int(s string) (int, bool) {
+	start := strings.IndexFunc(s, isDigit)
+	if start < 0 {
+		return 0, false
+	}
+	digits := &strings.Builder{}
+	i := start
+	for i < len(s) {
+		if isDigit(rune(s[i])) {
+			digits.WriteByte(s[i])
+			i++
+			continue
+		}
+		sep := separatorLen(s[i:])
+		if sep == 0 || !isGroup(s[i+sep:]) {
+			break
+		}
+		i += sep
+	}
+	if strings.HasPrefix(s[i:], ".") || strings.HasPrefix(s[i:], ",") {
+		if i+1 < len(s) && isDigit(rune(s[i+1])) {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(digits.String())
+	if err != nil {
+		return 0, false
+	}
+	if start > 0 && s[start-1] == '-' {
+		n = -n
+	}
+	return n, true
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// separatorLen returns the length of the thousands separator at the beginning of s, or 0
+func separatorLen(s string) int {
+	for _, sep := range []string{" ", "\u00a0", "."} {
+		if strings.HasPrefix(s, sep) {
+			return len(sep)
+		}
+	}
+	return 0
+}
+
+// isGroup reports whether s begins with exactly three digits
+func isGroup(s string) bool {
+	if len(s) < 3 || strings.IndexFunc(s[:3], func(r rune) bool { return !isDigit(r) }) >= 0 {
+		return false
+	}
+	return len(s) == 3 || !isDigit(rune(s[3]))
+}
+
+// Float parses a decimal number, with either '.' or ',' as the decimal separator.
+func Float(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
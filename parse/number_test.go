@@ -0,0 +1,43 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInt(t *testing.T) {
+	tests := map[string]int{
+		"42":                  42,
+		"12 345":              12345,
+		"12\u00a0345":         12345,
+		"1.234.567":           1234567,
+		"-3":                  -3,
+		"1 234 pont":          1234,
+		"3 (1 elküldve)":      3,
+		"összesen: 12 db":     12,
+		"12 345 (2 új)":       12345,
+		"1 2345":              1,
+		"Letöltve: 7 alkalom": 7,
+	}
+	for in, expected := range tests {
+		n, ok := Int(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, expected, n, in)
+	}
+	for _, in := range []string{"korlátlan", "1.5", "0,25 GiB", ""} {
+		_, ok := Int(in)
+		assert.False(t, ok, in)
+	}
+}
+
+func TestFloat(t *testing.T) {
+	f, ok := Float(" 1.234 ")
+	assert.True(t, ok)
+	assert.Equal(t, 1.234, f)
+	f, ok = Float("0,5")
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, f)
+	_, ok = Float("inf%")
+	assert.False(t, ok)
+}
@@ -49,6 +49,80 @@ func GetText(n *html.Node) string {
 	return strings.TrimSpace(sb.String())
 }
 
+// GetTextContent returns the text of the node and all of its descendants,
+// with the whitespace normalized. Scripts and styles are left out.
+func GetTextContent(n *html.Node) string {
+	sb := &strings.Builder{}
+	collectText(n, sb)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// inline elements do not separate the words of the surrounding text
+var inline = map[string]bool{
+	"a": true, "abbr": true, "b": true, "code": true, "em": true, "font": true, "i": true,
+	"small": true, "span": true, "strong": true, "sub": true, "sup": true, "u": true,
+}
+
+func collectText(n *html.Node, sb *strings.Builder) {
+	if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style") {
+		return
+	}
+	if n.Type == html.TextNode {
+		sb.WriteString(n.Data)
+	}
+	separate := n.Type == html.ElementNode && !inline[n.Data]
+	if separate {
+		sb.WriteString(" ")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		collectText(c, sb)
+	}
+	if separate {
+		sb.WriteString(" ")
+	}
+}
+
+// Field is a "dt" label, without the trailing colon, and the "dd" value following it.
+type Field struct {
+	Label string
+	Value *html.Node
+}
+
+type Fields []Field
+
+// Get returns the value of the first field with the given label, or nil.
+func (f Fields) Get(label string) *html.Node {
+	for _, field := range f {
+		if field.Label == label {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// GetFields pairs the "dt" labels with the "dd" values following them, in document order.
+func GetFields(n *html.Node) Fields {
+	fields := make(Fields, 0)
+	for _, dt := range GetElementsByClass(n, "dt") {
+		dd := nextElement(dt)
+		if dd == nil || !hasClass(dd, "dd") {
+			continue
+		}
+		label := strings.TrimSuffix(GetTextContent(dt), ":")
+		fields = append(fields, Field{Label: label, Value: dd})
+	}
+	return fields
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for s := n.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
 func FindAttr(n *html.Node, key string) (string, bool) {
 	for _, attr := range n.Attr {
 		if attr.Key == key {
@@ -148,6 +148,41 @@ func TestGetText(t *testing.T) {
 
 }
 
+func TestGetTextContent(t *testing.T) {
+	doc := MustParse(t, `
+	<div id="root">
+		<span>foo</span>
+		test
+		<script>var x = 1;</script>
+		<p>bar <b>baz</b>!</p><p>qux</p>
+	</div>`)
+	text := GetTextContent(GetElementById(doc, "root"))
+	assert.Equal(t, "foo test bar baz! qux", text)
+}
+
+func TestGetFields(t *testing.T) {
+	doc := MustParse(t, `
+	<div class="dt">Feltöltve:</div>
+	<div class="dd">2013-09-05 12:36:57</div>
+	<div class="dt">Feltöltő:</div>
+	<div class="dd"><span>Anonymous</span></div>
+	<div class="dt">Feltöltve:</div>
+	<div class="dd">ignored</div>
+	<div class="dt">Üres:</div>
+	<div class="dt">Méret:</div>
+	<div class="dd">1.69 GiB</div>`)
+	fields := GetFields(doc)
+	labels := make([]string, 0)
+	for _, f := range fields {
+		labels = append(labels, f.Label)
+	}
+	assert.Equal(t, []string{"Feltöltve", "Feltöltő", "Feltöltve", "Méret"}, labels)
+	assert.Equal(t, "2013-09-05 12:36:57", GetTextContent(fields.Get("Feltöltve")))
+	assert.Equal(t, "Anonymous", GetTextContent(fields.Get("Feltöltő")))
+	assert.Equal(t, "1.69 GiB", GetTextContent(fields.Get("Méret")))
+	assert.Nil(t, fields.Get("Üres"))
+}
+
 func getId(n *html.Node) string {
 	for _, attr := range n.Attr {
 		if attr.Key == "id" {
@@ -0,0 +1,34 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var sizeRegex = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*([KMGTP]i?B|B|bytes?)\b`)
+
+var sizeUnits = map[byte]float64{
+	'B': 1,
+	'K': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+	'T': 1 << 40,
+	'P': 1 << 50,
+}
+
+// Size converts a human readable size, like "1.37 GiB" into bytes. The site uses
+// binary units, so "GB" is treated the same as "GiB". Both '.' and ',' are accepted
+// as the decimal separator. The first size found in the text is used.
+func Size(s string) (int64, bool) {
+	m := sizeRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+	if err != nil {
+		return 0, false
+	}
+	unit := sizeUnits[strings.ToUpper(m[2])[0]]
+	return int64(n*unit + 0.5), true
+}
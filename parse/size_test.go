@@ -0,0 +1,30 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSize(t *testing.T) {
+	tests := map[string]int64{
+		"123 B":           123,
+		"1 KiB":           1024,
+		"1.5 MiB":         1572864,
+		"1.37 GiB":        1471026299,
+		"1,37 GiB":        1471026299,
+		"2 TiB":           2 << 40,
+		"700 MB":          700 << 20,
+		"Méret: 4.2 GiB ": 4509715661,
+		"12 bytes":        12,
+	}
+	for in, expected := range tests {
+		n, ok := Size(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, expected, n, in)
+	}
+	for _, in := range []string{"", "GiB", "foo", "12 apples"} {
+		_, ok := Size(in)
+		assert.False(t, ok, in)
+	}
+}
@@ -0,0 +1,76 @@
+package parse
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const siteZone = "Europe/Budapest"
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006.01.02. 15:04:05",
+	"2006.01.02. 15:04",
+	"2006.01.02.",
+}
+
+// the zone database is only read when the first time is parsed, and it may be missing
+// on systems without one, unless the program imports time/tzdata
+var loadLocation = sync.OnceValues(func() (*time.Location, error) {
+	return time.LoadLocation(siteZone)
+})
+
+var (
+	cet  = time.FixedZone("CET", 1*60*60)
+	cest = time.FixedZone("CEST", 2*60*60)
+)
+
+// Location returns the time zone of the times shown by the site. Without a zone
+// database it is a fixed CET zone, and Time applies the summer time rules itself.
+func Location() *time.Location {
+	if loc, err := loadLocation(); err == nil {
+		return loc
+	}
+	return cet
+}
+
+// Time parses a date or a date and time shown by the site, in the time zone of the site.
+func Time(s string) (time.Time, bool) {
+	s = strings.Join(strings.Fields(s), " ")
+	loc, loadErr := loadLocation()
+	for _, layout := range timeLayouts {
+		if loadErr == nil {
+			if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+				return t, true
+			}
+		} else if t, err := time.Parse(layout, s); err == nil {
+			return centralEuropean(t), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// centralEuropean moves the wall clock time of t into CET, or into CEST during
+// the summer time, which lasts from the last Sunday of March to the last Sunday
+// of October, changing at 01:00 UTC.
+func centralEuropean(t time.Time) time.Time {
+	at := func(loc *time.Location) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+	summer := at(cest)
+	start := lastSunday(t.Year(), time.March)
+	end := lastSunday(t.Year(), time.October)
+	if !summer.Before(start) && summer.Before(end) {
+		return summer
+	}
+	return at(cet)
+}
+
+// lastSunday returns 01:00 UTC on the last Sunday of the month.
+func lastSunday(year int, month time.Month) time.Time {
+	last := time.Date(year, month+1, 0, 1, 0, 0, 0, time.UTC)
+	return last.AddDate(0, 0, -int(last.Weekday()))
+}
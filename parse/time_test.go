@@ -0,0 +1,55 @@
+package parse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTime(t *testing.T) {
+
+	t.Run("summer time", func(t *testing.T) {
+		tm, ok := Time("2021-06-10 08:00:19")
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2021, 6, 10, 6, 0, 19, 0, time.UTC), tm.UTC())
+	})
+
+	t.Run("winter time", func(t *testing.T) {
+		tm, ok := Time("2021-01-10\n 08:00")
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2021, 1, 10, 7, 0, 0, 0, time.UTC), tm.UTC())
+	})
+
+	t.Run("hungarian date", func(t *testing.T) {
+		tm, ok := Time("2015.03.12.")
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2015, 3, 12, 0, 0, 0, 0, Location()), tm)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, ok := Time("tegnap")
+		assert.False(t, ok)
+	})
+
+}
+
+func TestCentralEuropean(t *testing.T) {
+	// the wall clock times, as parsed without a zone database
+	tests := map[time.Time]time.Time{
+		time.Date(2021, 6, 10, 8, 0, 19, 0, time.UTC): time.Date(2021, 6, 10, 6, 0, 19, 0, time.UTC),
+		time.Date(2021, 1, 10, 8, 0, 0, 0, time.UTC):  time.Date(2021, 1, 10, 7, 0, 0, 0, time.UTC),
+		time.Date(2021, 3, 28, 1, 59, 0, 0, time.UTC): time.Date(2021, 3, 28, 0, 59, 0, 0, time.UTC),
+		time.Date(2021, 3, 28, 3, 0, 0, 0, time.UTC):  time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 1, 0, 0, 0, time.UTC): time.Date(2021, 10, 30, 23, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 31, 4, 0, 0, 0, time.UTC): time.Date(2021, 10, 31, 3, 0, 0, 0, time.UTC),
+	}
+	for wall, expected := range tests {
+		assert.Equal(t, expected, centralEuropean(wall).UTC(), wall)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	// the zone database is available in the test environment
+	assert.Equal(t, siteZone, Location().String())
+}
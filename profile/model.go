@@ -0,0 +1,16 @@
+package profile
+
+import "time"
+
+type Profile struct {
+	UserName   string    `json:"userName"`
+	Class      string    `json:"class"`
+	Registered time.Time `json:"registered"`
+	LastSeen   time.Time `json:"lastSeen"`
+	// Uploaded and Downloaded are the total traffic of the account in bytes.
+	Uploaded   int64   `json:"uploaded"`
+	Downloaded int64   `json:"downloaded"`
+	Ratio      float64 `json:"ratio"`
+	Invites    int     `json:"invites"`
+	Bonus      int     `json:"bonus"`
+}
@@ -0,0 +1,64 @@
+package profile
+
+import (
+	"strings"
+
+	"github.com/gar-r/ngore/parse"
+	"golang.org/x/net/html"
+)
+
+// labels identify the fields of the profile by the beginning of their label,
+// the first matching label of the page is used for every field
+var labels = []struct {
+	field    string
+	prefixes []string
+}{
+	{"userName", []string{"felhasználónév"}},
+	{"class", []string{"rang", "osztály"}},
+	{"registered", []string{"regisztrált", "regisztráció"}},
+	{"lastSeen", []string{"utolsó belépés", "utoljára"}},
+	{"uploaded", []string{"feltöltés", "feltöltött adat"}},
+	{"downloaded", []string{"letöltés", "letöltött adat"}},
+	{"ratio", []string{"arány"}},
+	{"invites", []string{"meghívó"}},
+	{"bonus", []string{"bónusz"}},
+}
+
+func ParseResponse(doc *html.Node) *Profile {
+	fields := parseFields(doc)
+	p := &Profile{
+		UserName: fields["userName"],
+		Class:    fields["class"],
+	}
+	p.Registered, _ = parse.Time(fields["registered"])
+	p.LastSeen, _ = parse.Time(fields["lastSeen"])
+	p.Uploaded, _ = parse.Size(fields["uploaded"])
+	p.Downloaded, _ = parse.Size(fields["downloaded"])
+	p.Ratio, _ = parse.Float(fields["ratio"])
+	p.Invites, _ = parse.Int(fields["invites"])
+	p.Bonus, _ = parse.Int(fields["bonus"])
+	return p
+}
+
+func parseFields(doc *html.Node) map[string]string {
+	fields := make(map[string]string)
+	for _, f := range parse.GetFields(doc) {
+		name := fieldName(f.Label)
+		if _, ok := fields[name]; name != "" && !ok {
+			fields[name] = parse.GetTextContent(f.Value)
+		}
+	}
+	return fields
+}
+
+func fieldName(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	for _, l := range labels {
+		for _, prefix := range l.prefixes {
+			if strings.HasPrefix(label, prefix) {
+				return l.field
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package profile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gar-r/ngore/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResponse(t *testing.T) {
+
+	t.Run("parse profile", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div class="fobox_tartalom">
+			<div class="dt">Felhasználónév:</div>
+			<div class="dd">someone</div>
+			<div class="dt">Rang:</div>
+			<div class="dd"><span class="rang_szin">Power User</span></div>
+			<div class="dt">Regisztrált:</div>
+			<div class="dd">2015-03-12 10:20:30</div>
+			<div class="dt">Utolsó belépés:</div>
+			<div class="dd">2021-06-10 08:00:19</div>
+			<div class="dt">Feltöltés:</div>
+			<div class="dd">1.5 TiB</div>
+			<div class="dt">Letöltés:</div>
+			<div class="dd">512 GiB</div>
+			<div class="dt">Arány:</div>
+			<div class="dd">3.000</div>
+			<div class="dt">Meghívók:</div>
+			<div class="dd">2 (1 elküldve)</div>
+			<div class="dt">Bónuszpont:</div>
+			<div class="dd">12 345</div>
+			<div class="dt">Egyéb:</div>
+			<div class="dd">ignored</div>
+		</div>`)
+		p := ParseResponse(doc)
+		assert.Equal(t, &Profile{
+			UserName:   "someone",
+			Class:      "Power User",
+			Registered: time.Date(2015, 3, 12, 10, 20, 30, 0, parse.Location()),
+			LastSeen:   time.Date(2021, 6, 10, 8, 0, 19, 0, parse.Location()),
+			Uploaded:   3 << 39,
+			Downloaded: 512 << 30,
+			Ratio:      3,
+			Invites:    2,
+			Bonus:      12345,
+		}, p)
+	})
+
+	t.Run("first matching label used", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div class="dt">Feltöltés:</div>
+		<div class="dd">1 GiB</div>
+		<div class="dt">Feltöltött torrentek:</div>
+		<div class="dd">5 MiB</div>`)
+		assert.Equal(t, &Profile{Uploaded: 1 << 30}, ParseResponse(doc))
+	})
+
+	t.Run("similar labels ignored", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div class="dt">Név:</div>
+		<div class="dd">Not the user name</div>
+		<div class="dt">Feltöltött torrentek:</div>
+		<div class="dd">5 MiB</div>
+		<div class="dt">Feltöltés:</div>
+		<div class="dd">1 GiB</div>
+		<div class="dt">Pontszám:</div>
+		<div class="dd">7</div>`)
+		assert.Equal(t, &Profile{Uploaded: 1 << 30}, ParseResponse(doc))
+	})
+
+	t.Run("missing values", func(t *testing.T) {
+		doc := parse.MustParse(t, `
+		<div class="dt">Regisztrált:</div>
+		<div class="dd">-</div>
+		<div class="dt">Arány:</div>`)
+		assert.Equal(t, &Profile{}, ParseResponse(doc))
+	})
+
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// Requests is the number of requests allowed in every Interval.
+	// Zero disables the rate limit.
+	Requests int
+	Interval time.Duration
+	// Burst is the number of requests allowed back to back,
+	// before the rate limit kicks in. It defaults to 1.
+	Burst int
+	// MinSpacing is the minimum time between two requests.
+	MinSpacing time.Duration
+	// Jitter is the upper bound of a random delay added before every request.
+	Jitter time.Duration
+}
+
+// Limiter paces requests according to its Config. It is safe for
+// concurrent use, and can be shared between several api instances.
+type Limiter struct {
+	mu        sync.Mutex
+	emission  time.Duration
+	tolerance time.Duration
+	spacing   time.Duration
+	jitter    time.Duration
+	tat       time.Time
+	last      time.Time
+}
+
+func New(cfg Config) *Limiter {
+	l := &Limiter{
+		spacing: cfg.MinSpacing,
+		jitter:  cfg.Jitter,
+	}
+	if cfg.Requests > 0 && cfg.Interval > 0 {
+		burst := max(cfg.Burst, 1)
+		l.emission = cfg.Interval / time.Duration(cfg.Requests)
+		l.tolerance = l.emission * time.Duration(burst-1)
+	}
+	return l
+}
+
+// Wait blocks until the next request is allowed, or the context is done.
+// A request given up while waiting returns its slot.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var jitter time.Duration
+	if l.jitter > 0 {
+		jitter = rand.N(l.jitter)
+	}
+	now := time.Now()
+	r := l.reserve(now, jitter)
+	delay := r.at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel(r)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reservation is a booked slot, with what is needed to give it back
+type reservation struct {
+	at       time.Time
+	prevLast time.Time
+}
+
+// reserve books the slot of the next request using the generic cell rate algorithm,
+// and returns the time the request is allowed at. The jitter is part of the slot,
+// so the spacing is kept between the actual requests.
+func (l *Limiter) reserve(now time.Time, jitter time.Duration) reservation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	at := now
+	if !l.last.IsZero() {
+		at = later(at, l.last.Add(l.spacing))
+	}
+	if l.emission > 0 {
+		tat := later(l.tat, at)
+		at = later(at, tat.Add(-l.tolerance))
+		l.tat = tat.Add(l.emission)
+	}
+	at = at.Add(jitter)
+	r := reservation{at: at, prevLast: l.last}
+	l.last = at
+	return r
+}
+
+// cancel gives back a slot that is not used. The spacing can only be given back
+// by the latest reservation, the ones after it are already booked.
+func (l *Limiter) cancel(r reservation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.emission > 0 {
+		l.tat = l.tat.Add(-l.emission)
+	}
+	if l.last.Equal(r.at) {
+		l.last = r.prevLast
+	}
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_reserve(t *testing.T) {
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no limits", func(t *testing.T) {
+		l := New(Config{})
+		for range 3 {
+			assert.Equal(t, start, l.reserve(start, 0).at)
+		}
+	})
+
+	t.Run("requests per interval", func(t *testing.T) {
+		l := New(Config{Requests: 2, Interval: time.Second})
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		assert.Equal(t, start.Add(500*time.Millisecond), l.reserve(start, 0).at)
+		assert.Equal(t, start.Add(time.Second), l.reserve(start, 0).at)
+	})
+
+	t.Run("burst", func(t *testing.T) {
+		l := New(Config{Requests: 1, Interval: time.Second, Burst: 3})
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		assert.Equal(t, start.Add(time.Second), l.reserve(start, 0).at)
+	})
+
+	t.Run("burst refills over time", func(t *testing.T) {
+		l := New(Config{Requests: 1, Interval: time.Second, Burst: 2})
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		later := start.Add(5 * time.Second)
+		assert.Equal(t, later, l.reserve(later, 0).at)
+		assert.Equal(t, later, l.reserve(later, 0).at)
+		assert.Equal(t, later.Add(time.Second), l.reserve(later, 0).at)
+	})
+
+	t.Run("min spacing", func(t *testing.T) {
+		l := New(Config{MinSpacing: 200 * time.Millisecond})
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		assert.Equal(t, start.Add(200*time.Millisecond), l.reserve(start, 0).at)
+		assert.Equal(t, start.Add(400*time.Millisecond), l.reserve(start.Add(100*time.Millisecond), 0).at)
+	})
+
+	t.Run("jitter part of the slot", func(t *testing.T) {
+		l := New(Config{MinSpacing: 500 * time.Millisecond})
+		assert.Equal(t, start.Add(240*time.Millisecond), l.reserve(start, 240*time.Millisecond).at)
+		assert.Equal(t, start.Add(750*time.Millisecond), l.reserve(start, 10*time.Millisecond).at)
+	})
+
+	t.Run("cancelled slot given back", func(t *testing.T) {
+		l := New(Config{Requests: 1, Interval: time.Second, MinSpacing: 200 * time.Millisecond})
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		l.cancel(l.reserve(start, 0))
+		assert.Equal(t, start.Add(time.Second), l.reserve(start, 0).at)
+	})
+
+	t.Run("only the rate given back by an earlier slot", func(t *testing.T) {
+		l := New(Config{Requests: 1, Interval: time.Second})
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		r := l.reserve(start, 0)
+		assert.Equal(t, start.Add(2*time.Second), l.reserve(start, 0).at)
+		l.cancel(r)
+		assert.Equal(t, start.Add(2*time.Second), l.reserve(start, 0).at)
+	})
+
+	t.Run("min spacing within burst", func(t *testing.T) {
+		l := New(Config{Requests: 1, Interval: time.Second, Burst: 5, MinSpacing: 100 * time.Millisecond})
+		assert.Equal(t, start, l.reserve(start, 0).at)
+		assert.Equal(t, start.Add(100*time.Millisecond), l.reserve(start, 0).at)
+	})
+
+}
+
+func TestLimiter_Wait(t *testing.T) {
+
+	t.Run("waits for the next slot", func(t *testing.T) {
+		l := New(Config{MinSpacing: 50 * time.Millisecond})
+		begin := time.Now()
+		assert.NoError(t, l.Wait(context.Background()))
+		assert.NoError(t, l.Wait(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(begin), 50*time.Millisecond)
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		l := New(Config{Jitter: 10 * time.Millisecond})
+		assert.NoError(t, l.Wait(context.Background()))
+	})
+
+	t.Run("context cancelled while waiting", func(t *testing.T) {
+		l := New(Config{MinSpacing: time.Hour})
+		assert.NoError(t, l.Wait(context.Background()))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("cancelled wait does not delay later callers", func(t *testing.T) {
+		l := New(Config{MinSpacing: time.Hour})
+		assert.NoError(t, l.Wait(context.Background()))
+		first := l.last
+		for range 3 {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+			assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+			cancel()
+		}
+		assert.Equal(t, first.Add(time.Hour), l.reserve(first, 0).at)
+	})
+
+}
@@ -0,0 +1,109 @@
+package retry
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type Policy struct {
+	// MaxAttempts is the number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, which doubles
+	// for every further retry, up to MaxDelay. When the response asks
+	// for a longer pause with Retry-After, it is not retried at all.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Jitter is the fraction of the delay, which is randomized
+	// to spread out the retries of concurrent callers, between 0 and 1.
+	Jitter float64
+	// RetryableStatus lists the response status codes worth retrying.
+	RetryableStatus []int
+	// RetryableError decides whether a transport error is worth retrying.
+	// Defaults to IsTransient when nil.
+	RetryableError func(err error) bool
+}
+
+func Default() *Policy {
+	return &Policy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.5,
+		RetryableStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// Delay returns the delay before the given retry, starting from 1.
+func (p *Policy) Delay(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	jitter := time.Duration(float64(d) * min(max(p.Jitter, 0), 1))
+	if jitter > 0 {
+		d = d - jitter + rand.N(jitter)
+	}
+	return d
+}
+
+func (p *Policy) RetryStatus(code int) bool {
+	return slices.Contains(p.RetryableStatus, code)
+}
+
+func (p *Policy) RetryError(err error) bool {
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	return IsTransient(err)
+}
+
+// RetryAfter returns the delay requested by the Retry-After response header,
+// or zero when the header is missing or invalid.
+func RetryAfter(res *http.Response) time.Duration {
+	h := res.Header.Get("Retry-After")
+	if h == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(h); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// IsTransient reports whether the error is a network failure,
+// which might not happen again, like a timeout or a reset connection.
+func IsTransient(err error) bool {
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &opErr):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Delay(t *testing.T) {
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		p := &Policy{BaseDelay: 100 * time.Millisecond}
+		assert.Equal(t, 100*time.Millisecond, p.Delay(1))
+		assert.Equal(t, 200*time.Millisecond, p.Delay(2))
+		assert.Equal(t, 400*time.Millisecond, p.Delay(3))
+	})
+
+	t.Run("max delay", func(t *testing.T) {
+		p := &Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond}
+		assert.Equal(t, 250*time.Millisecond, p.Delay(3))
+		assert.Equal(t, 250*time.Millisecond, p.Delay(100))
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		p := &Policy{BaseDelay: 100 * time.Millisecond, Jitter: 0.5}
+		for range 100 {
+			d := p.Delay(1)
+			assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+			assert.Less(t, d, 100*time.Millisecond)
+		}
+	})
+
+}
+
+func TestPolicy_RetryStatus(t *testing.T) {
+	p := Default()
+	assert.True(t, p.RetryStatus(http.StatusServiceUnavailable))
+	assert.True(t, p.RetryStatus(http.StatusTooManyRequests))
+	assert.False(t, p.RetryStatus(http.StatusNotFound))
+	assert.False(t, p.RetryStatus(http.StatusFound))
+}
+
+func TestPolicy_RetryError(t *testing.T) {
+
+	t.Run("default classification", func(t *testing.T) {
+		p := Default()
+		assert.True(t, p.RetryError(io.ErrUnexpectedEOF))
+		assert.False(t, p.RetryError(errors.New("test error")))
+	})
+
+	t.Run("custom classification", func(t *testing.T) {
+		p := &Policy{RetryableError: func(err error) bool {
+			return err.Error() == "test error"
+		}}
+		assert.True(t, p.RetryError(errors.New("test error")))
+		assert.False(t, p.RetryError(io.ErrUnexpectedEOF))
+	})
+
+}
+
+func TestRetryAfter(t *testing.T) {
+
+	response := func(value string) *http.Response {
+		res := &http.Response{Header: http.Header{}}
+		if value != "" {
+			res.Header.Set("Retry-After", value)
+		}
+		return res
+	}
+
+	t.Run("seconds", func(t *testing.T) {
+		assert.Equal(t, 5*time.Second, RetryAfter(response("5")))
+	})
+
+	t.Run("http date", func(t *testing.T) {
+		d := RetryAfter(response(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)))
+		assert.Greater(t, d, 50*time.Second)
+		assert.LessOrEqual(t, d, time.Minute)
+	})
+
+	t.Run("date in the past", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), RetryAfter(response("Wed, 21 Oct 2015 07:28:00 GMT")))
+	})
+
+	t.Run("missing or invalid", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), RetryAfter(response("")))
+		assert.Equal(t, time.Duration(0), RetryAfter(response("soon")))
+	})
+
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+
+	wrap := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://example.com", Err: err}
+	}
+
+	t.Run("transient errors", func(t *testing.T) {
+		assert.True(t, IsTransient(wrap(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED})))
+		assert.True(t, IsTransient(wrap(timeoutError{})))
+		assert.True(t, IsTransient(wrap(io.EOF)))
+		assert.True(t, IsTransient(fmt.Errorf("read: %w", syscall.ECONNRESET)))
+	})
+
+	t.Run("permanent errors", func(t *testing.T) {
+		assert.False(t, IsTransient(wrap(errors.New(`unsupported protocol scheme ""`))))
+	})
+
+}
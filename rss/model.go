@@ -0,0 +1,18 @@
+package rss
+
+import "time"
+
+type Feed struct {
+	Title string  `json:"title"`
+	Items []*Item `json:"items"`
+}
+
+type Item struct {
+	Id          string    `json:"id"`
+	Title       string    `json:"title"`
+	Category    string    `json:"category"`
+	Size        int64     `json:"size"`
+	Published   time.Time `json:"published"`
+	DownloadUrl string    `json:"downloadUrl"`
+	DetailsUrl  string    `json:"detailsUrl"`
+}
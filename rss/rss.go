@@ -0,0 +1,119 @@
+package rss
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gar-r/ngore/parse"
+)
+
+var dateLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC3339}
+
+type document struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Guid        string `xml:"guid"`
+	Comments    string `xml:"comments"`
+	Source      source `xml:"source"`
+	Category    string `xml:"category"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+	Size        string `xml:"size"`
+	Enclosure   struct {
+		Url    string `xml:"url,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+}
+
+type source struct {
+	Url string `xml:"url,attr"`
+}
+
+func ParseFeed(r io.Reader) (*Feed, error) {
+	doc := &document{}
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return nil, err
+	}
+	feed := &Feed{
+		Title: strings.TrimSpace(doc.Channel.Title),
+		Items: make([]*Item, 0, len(doc.Channel.Items)),
+	}
+	for _, i := range doc.Channel.Items {
+		feed.Items = append(feed.Items, parseItem(i))
+	}
+	return feed, nil
+}
+
+func parseItem(i rssItem) *Item {
+	item := &Item{
+		Title:       strings.TrimSpace(i.Title),
+		Category:    strings.TrimSpace(i.Category),
+		Published:   parseDate(i.PubDate),
+		DownloadUrl: downloadUrl(i),
+		DetailsUrl:  detailsUrl(i),
+	}
+	item.Id = firstNonEmpty(
+		torrentId(item.DownloadUrl),
+		torrentId(item.DetailsUrl),
+		torrentId(i.Guid),
+	)
+	item.Size = i.Enclosure.Length
+	if item.Size == 0 {
+		item.Size, _ = parse.Size(i.Size + " " + i.Description)
+	}
+	return item
+}
+
+func downloadUrl(i rssItem) string {
+	if i.Enclosure.Url != "" {
+		return strings.TrimSpace(i.Enclosure.Url)
+	}
+	return strings.TrimSpace(i.Link)
+}
+
+func detailsUrl(i rssItem) string {
+	for _, u := range []string{i.Source.Url, i.Comments, i.Guid, i.Link} {
+		u = strings.TrimSpace(u)
+		if strings.Contains(u, "action=details") {
+			return u
+		}
+	}
+	return ""
+}
+
+func torrentId(s string) string {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("id")
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
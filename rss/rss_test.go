@@ -0,0 +1,81 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const feed = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0">
+<channel>
+	<title>nCore RSS</title>
+	<item>
+		<title><![CDATA[Some.Movie.2020.1080p.BluRay]]></title>
+		<link>https://ncore.pro/torrents.php?action=download&amp;id=1234&amp;key=abc</link>
+		<source url="https://ncore.pro/torrents.php?action=details&amp;id=1234">nCore</source>
+		<category>hd_hun</category>
+		<pubDate>Sat, 03 Jun 2023 10:15:00 +0200</pubDate>
+		<description><![CDATA[Méret: 1.37 GiB]]></description>
+	</item>
+	<item>
+		<title>Other.Series.S01E01</title>
+		<guid>https://ncore.pro/torrents.php?action=details&amp;id=5678</guid>
+		<category>hdser</category>
+		<pubDate>bad date</pubDate>
+		<enclosure url="https://ncore.pro/torrents.php?action=download&amp;id=5678&amp;key=abc" length="1024" type="application/x-bittorrent"/>
+	</item>
+</channel>
+</rss>`
+
+func TestParseFeed(t *testing.T) {
+
+	t.Run("items", func(t *testing.T) {
+		f, err := ParseFeed(strings.NewReader(feed))
+		assert.NoError(t, err)
+		assert.Equal(t, "nCore RSS", f.Title)
+		assert.Equal(t, []*Item{
+			{
+				Id:          "1234",
+				Title:       "Some.Movie.2020.1080p.BluRay",
+				Category:    "hd_hun",
+				Size:        1471026299,
+				Published:   time.Date(2023, 6, 3, 8, 15, 0, 0, time.UTC),
+				DownloadUrl: "https://ncore.pro/torrents.php?action=download&id=1234&key=abc",
+				DetailsUrl:  "https://ncore.pro/torrents.php?action=details&id=1234",
+			},
+			{
+				Id:          "5678",
+				Title:       "Other.Series.S01E01",
+				Category:    "hdser",
+				Size:        1024,
+				DownloadUrl: "https://ncore.pro/torrents.php?action=download&id=5678&key=abc",
+				DetailsUrl:  "https://ncore.pro/torrents.php?action=details&id=5678",
+			},
+		}, normalize(f.Items))
+	})
+
+	t.Run("empty feed", func(t *testing.T) {
+		f, err := ParseFeed(strings.NewReader(`<rss><channel></channel></rss>`))
+		assert.NoError(t, err)
+		assert.Empty(t, f.Items)
+	})
+
+	t.Run("not xml", func(t *testing.T) {
+		_, err := ParseFeed(strings.NewReader(`<html><body>error`))
+		assert.Error(t, err)
+	})
+
+}
+
+// normalize converts the publish times to UTC, so they can be compared with assert.Equal
+func normalize(items []*Item) []*Item {
+	for _, i := range items {
+		if !i.Published.IsZero() {
+			i.Published = i.Published.UTC()
+		}
+	}
+	return items
+}
@@ -1,12 +1,29 @@
 package search
 
+import "time"
+
 type Params struct {
-	SearchPhrase string    `json:"searchPhrase"`
-	Field        Field     `json:"field"`
-	Category     Category  `json:"category"`
-	SortField    SortField `json:"sortField"`
-	SortMode     SortMode  `json:"sortMode"`
-	Page         int       `json:"page"`
+	SearchPhrase string   `json:"searchPhrase"`
+	Field        Field    `json:"field"`
+	Category     Category `json:"category"`
+	// Categories searches in several categories at once, instead of Category.
+	// Groups, like AllMovies can be combined with slices.Concat.
+	Categories []Category `json:"categories,omitempty"`
+	SortField  SortField  `json:"sortField"`
+	SortMode   SortMode   `json:"sortMode"`
+	Page       int        `json:"page"`
+}
+
+// Limit stops iterating over the results, after the given number of results or pages.
+// Zero values mean no limit.
+type Limit struct {
+	MaxResults int `json:"maxResults"`
+	MaxPages   int `json:"maxPages"`
+}
+
+// Reached reports whether the limit is reached after the given number of results and pages.
+func (l *Limit) Reached(results int, pages int) bool {
+	return (l.MaxResults > 0 && results >= l.MaxResults) || (l.MaxPages > 0 && pages >= l.MaxPages)
 }
 
 type Result struct {
@@ -21,15 +38,35 @@ type Torrent struct {
 	Uploaded string `json:"uploaded"`
 	Uploader string `json:"uploader"`
 	Size     string `json:"size"`
-	Health   string `json:"health"`
-	Seeds    string `json:"seeds"`
-	Peers    string `json:"peers"`
+	// Health is the raw value of the times completed column, kept under its old name.
+	Health string `json:"health"`
+	Seeds  string `json:"seeds"`
+	Peers  string `json:"peers"`
+
+	// Category is the code of the category used in searches, like "hd_hun".
+	Category     string  `json:"category"`
+	CategoryName string  `json:"categoryName"`
+	CategoryIcon string  `json:"categoryIcon"`
+	Freeleech    bool    `json:"freeleech"`
+	NoHitAndRun  bool    `json:"noHitAndRun"`
+	ImdbRating   float64 `json:"imdbRating"`
+	Cover        string  `json:"cover"`
+	// DownloadUrl is the direct link of the torrent file, including the api key.
+	DownloadUrl string `json:"downloadUrl"`
+
+	// typed values of the raw strings above, left zero when a value cannot be parsed
+	SizeBytes  int64     `json:"sizeBytes"`
+	UploadedAt time.Time `json:"uploadedAt"`
+	Seeders    int       `json:"seeders"`
+	Leechers   int       `json:"leechers"`
+	Completed  int       `json:"completed"`
 }
 
 type PageInfo struct {
 	Current int `json:"current"`
 	Prev    int `json:"prev"`
 	Next    int `json:"next"`
+	Last    int `json:"last"`
 }
 
 func (p *PageInfo) HasMore() bool {
@@ -97,6 +134,18 @@ const (
 	AllOwn
 )
 
+// Category groups, for searching in every category of a kind, or of a language.
+var (
+	AllMovies = []Category{MovieSdHu, MovieSdEn, MovieDvdHu, MovieDvdEn, MovieDvd9Hu, MovieDvd9En, MovieHdHu, MovieHdEn}
+	AllSeries = []Category{SeriesSdHu, SeriesSdEn, SeriesDvdHu, SeriesDvdEn, SeriesHdHu, SeriesHdEn}
+	AllMusic  = []Category{Mp3Hu, Mp3En, LosslessHu, LosslessEn, Clip}
+	AllGames  = []Category{GameIso, GameRip, Console}
+	AllBooks  = []Category{EbookHu, EbookEn}
+	AllXxx    = []Category{XImg, XSd, XDvd, XHd}
+	AllHu     = []Category{MovieSdHu, MovieDvdHu, MovieDvd9Hu, MovieHdHu, SeriesSdHu, SeriesDvdHu, SeriesHdHu, Mp3Hu, LosslessHu, EbookHu}
+	AllEn     = []Category{MovieSdEn, MovieDvdEn, MovieDvd9En, MovieHdEn, SeriesSdEn, SeriesDvdEn, SeriesHdEn, Mp3En, LosslessEn, EbookEn}
+)
+
 func (s Category) String() string {
 	switch s {
 	case MovieSdEn:
@@ -3,6 +3,7 @@ package search
 import (
 	"github.com/gar-r/ngore/parse"
 	"golang.org/x/net/html"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -10,6 +11,8 @@ import (
 const defaultPage = 1
 const pageSize = 25
 
+var pageRegex = regexp.MustCompile(`[?&]oldal=(\d+)`)
+
 func parsePageInfo(n *html.Node) (pi *PageInfo) {
 	pi = &PageInfo{}
 	pager := parse.GetElementById(n, "pager_bottom")
@@ -17,6 +20,7 @@ func parsePageInfo(n *html.Node) (pi *PageInfo) {
 		pi.Current = parseCurrent(n)
 		pi.Prev = parsePrev(n)
 		pi.Next = parseNext(n)
+		pi.Last = parseLast(pager, pi.Current)
 	}
 	return
 }
@@ -48,6 +52,22 @@ func parseNext(n *html.Node) int {
 	return calcPageNumber(parse.GetText(str))
 }
 
+// parseLast finds the highest page linked from the pager. The link to the last page
+// carries the page number in its url, the other links show the range of the results.
+func parseLast(pager *html.Node, current int) int {
+	last := current
+	for _, a := range parse.GetElementsByTag(pager, "a") {
+		if m := pageRegex.FindStringSubmatch(hrefAttr(a)); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			last = max(last, n)
+		}
+	}
+	for _, str := range parse.GetElementsByTag(pager, "strong") {
+		last = max(last, calcPageNumber(parse.GetText(str)))
+	}
+	return last
+}
+
 func calcPageNumber(s string) int {
 	parts := strings.Split(s, "-")
 	if len(parts) != 2 {
@@ -1,7 +1,9 @@
 package search
 
 import (
+	"net/url"
 	"regexp"
+	"strings"
 
 	"github.com/gar-r/ngore/parse"
 	"golang.org/x/net/html"
@@ -9,6 +11,12 @@ import (
 
 var idRegex = regexp.MustCompile(`.*id=(\d*)`)
 
+// coverRegex matches the url of the cover in the hover handler, like "mutat('https://...', '300', ...)"
+var coverRegex = regexp.MustCompile(`mutat\('([^']+)'`)
+
+// imdbRegex matches the rating shown next to the title, like "[imdb: 7.4]"
+var imdbRegex = regexp.MustCompile(`(?i)imdb:\s*(\d+(?:[.,]\d+)?)`)
+
 func ParseResponse(doc *html.Node) *Result {
 	return &Result{
 		Torrents: parseTorrents(doc),
@@ -33,11 +41,43 @@ func parseTorrents(doc *html.Node) []*Torrent {
 		t.Size = extractSize(node)
 		t.Uploaded = extractUploaded(node)
 		t.Uploader = extractUploader(node)
+		t.Category, t.CategoryName, t.CategoryIcon = extractCategory(node)
+		t.Freeleech = parse.HasBadge(node, parse.FreeleechBadges)
+		t.NoHitAndRun = parse.HasBadge(node, parse.NoHitAndRunBadges)
+		t.ImdbRating = extractImdbRating(node)
+		t.Cover = extractCover(node)
+		t.DownloadUrl = extractDownloadUrl(node)
+		parseTypedValues(t)
 		torrents = append(torrents, t)
 	}
 	return torrents
 }
 
+// Merge joins the torrents of several pages in order. A torrent that appears on more than
+// one page, because the results shifted between the requests, is only kept the first time.
+func Merge(pages ...[]*Torrent) []*Torrent {
+	torrents := make([]*Torrent, 0)
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		for _, t := range page {
+			if t.Id != "" && seen[t.Id] {
+				continue
+			}
+			seen[t.Id] = true
+			torrents = append(torrents, t)
+		}
+	}
+	return torrents
+}
+
+func parseTypedValues(t *Torrent) {
+	t.SizeBytes, _ = parse.Size(t.Size)
+	t.UploadedAt, _ = parse.Time(t.Uploaded)
+	t.Seeders, _ = parse.Int(t.Seeds)
+	t.Leechers, _ = parse.Int(t.Peers)
+	t.Completed, _ = parse.Int(t.Health)
+}
+
 func extractId(n *html.Node) string {
 	a := parse.GetElementByTag(n, "a")
 	if a != nil {
@@ -78,6 +118,68 @@ func extractAltTitle(n *html.Node) string {
 	return titleAttr(span)
 }
 
+// extractCategory returns the code, the name and the icon of the category from the
+// image link at the beginning of the row
+func extractCategory(n *html.Node) (string, string, string) {
+	node := parse.GetElementByClass(n, "box_alap_img")
+	if node == nil {
+		return "", "", ""
+	}
+	code := ""
+	if a := parse.GetElementByTag(node, "a"); a != nil {
+		if u, err := url.Parse(hrefAttr(a)); err == nil {
+			code = u.Query().Get("tipus")
+		}
+	}
+	img := parse.GetElementByTag(node, "img")
+	if img == nil {
+		return code, "", ""
+	}
+	name, ok := parse.FindAttr(img, "alt")
+	if !ok {
+		name = titleAttr(img)
+	}
+	icon, _ := parse.FindAttr(img, "src")
+	return code, name, icon
+}
+
+func extractImdbRating(n *html.Node) float64 {
+	node := getTxtNode(n)
+	if node == nil {
+		return 0
+	}
+	m := imdbRegex.FindStringSubmatch(parse.GetTextContent(node))
+	if m == nil {
+		return 0
+	}
+	rating, _ := parse.Float(m[1])
+	return rating
+}
+
+func extractCover(n *html.Node) string {
+	node := parse.GetElementByClass(n, "infobar")
+	if node == nil {
+		return ""
+	}
+	for _, img := range parse.GetElementsByTag(node, "img") {
+		handler, _ := parse.FindAttr(img, "onmouseover")
+		if m := coverRegex.FindStringSubmatch(handler); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+func extractDownloadUrl(n *html.Node) string {
+	for _, a := range parse.GetElementsByTag(n, "a") {
+		href := hrefAttr(a)
+		if strings.Contains(href, "action=download") {
+			return href
+		}
+	}
+	return ""
+}
+
 func extractHealth(n *html.Node) string {
 	node := parse.GetElementByClass(n, "box_d2")
 	if node == nil {
@@ -2,6 +2,7 @@ package search
 
 import (
 	"testing"
+	"time"
 
 	"github.com/gar-r/ngore/parse"
 	"github.com/stretchr/testify/assert"
@@ -71,6 +72,15 @@ func TestApi_Search(t *testing.T) {
 				Size:     "699.82 MiB",
 				Uploaded: "2021-06-10 08:00:19",
 				Uploader: "Anonymous",
+
+				Category:     "xvid_hun",
+				CategoryName: "SD/HU",
+				CategoryIcon: "https://static.ncore.pro/styles/brutecore/ico/ico_xvid_hun.png",
+				Cover:        "https://nc-img.cdn.l7cache.com/covers/L9_kMzZ3fwZFl_Zl?27055080",
+
+				SizeBytes:  733814456,
+				UploadedAt: time.Date(2021, 6, 10, 8, 0, 19, 0, parse.Location()),
+				Seeders:    6,
 			},
 		}
 		assert.Equal(t, expected, results.Torrents)
@@ -407,6 +417,91 @@ func TestApi_Search(t *testing.T) {
 
 	})
 
+	t.Run("typed values", func(t *testing.T) {
+
+		t.Run("values parsed", func(t *testing.T) {
+			doc := parse.MustParse(t, `
+			<div class="box_torrent">
+				<div class="box_feltoltve2">2021-01-10<br>08:00:19</div>
+				<div class="box_meret2">1.5 GiB</div>
+				<div class="box_d2">1 234</div>
+				<div class="box_s2"><a>12</a></div>
+				<div class="box_l2"><a>3</a></div>
+			</div>`)
+			results := ParseResponse(doc)
+			tr := results.Torrents[0]
+			assert.Equal(t, int64(3<<29), tr.SizeBytes)
+			assert.Equal(t, time.Date(2021, 1, 10, 7, 0, 19, 0, time.UTC), tr.UploadedAt.UTC())
+			assert.Equal(t, 1234, tr.Completed)
+			assert.Equal(t, 12, tr.Seeders)
+			assert.Equal(t, 3, tr.Leechers)
+			assert.Equal(t, "1.5 GiB", tr.Size)
+		})
+
+		t.Run("invalid values left zero", func(t *testing.T) {
+			doc := parse.MustParse(t, `
+			<div class="box_torrent">
+				<div class="box_feltoltve2">tegnap</div>
+				<div class="box_meret2">?</div>
+				<div class="box_d2">++</div>
+			</div>`)
+			results := ParseResponse(doc)
+			tr := results.Torrents[0]
+			assert.Zero(t, tr.SizeBytes)
+			assert.True(t, tr.UploadedAt.IsZero())
+			assert.Zero(t, tr.Completed)
+			assert.Equal(t, "++", tr.Health)
+		})
+
+	})
+
+	t.Run("row details", func(t *testing.T) {
+
+		t.Run("badges, rating and download link", func(t *testing.T) {
+			doc := parse.MustParse(t, `
+			<div class="box_torrent">
+				<div class="box_nagy">
+					<div class="torrent_txt">
+						<a href="torrents.php?action=details&id=42" title="Foo"><nobr>Foo</nobr></a>
+						<div class="torrent_txt_also">
+							<div class="infobar"><a class="infolink" href="https://imdb.com/title/tt0000042/">[imdb: 7,4]</a></div>
+						</div>
+					</div>
+					<img class="freeleech" alt="Freeleech" src="freeleech.png">
+					<img class="nohnr" src="nohnr.png">
+					<a href="torrents.php?action=download&id=42&key=abc"><img src="download.png"></a>
+				</div>
+			</div>`)
+			tr := ParseResponse(doc).Torrents[0]
+			assert.True(t, tr.Freeleech)
+			assert.True(t, tr.NoHitAndRun)
+			assert.Equal(t, 7.4, tr.ImdbRating)
+			assert.Equal(t, "torrents.php?action=download&id=42&key=abc", tr.DownloadUrl)
+		})
+
+		t.Run("missing values", func(t *testing.T) {
+			doc := parse.MustParse(t, `
+			<div class="box_torrent">
+				<div class="box_alap_img"><a href="#"></a></div>
+				<div class="box_nagy">
+					<div class="torrent_txt">
+						<a href="torrents.php?action=details&id=42" title="Foo"></a>
+						<div class="infobar"><img onmouseover="elrejt()"></div>
+					</div>
+				</div>
+			</div>`)
+			tr := ParseResponse(doc).Torrents[0]
+			assert.Empty(t, tr.Category)
+			assert.Empty(t, tr.CategoryName)
+			assert.Empty(t, tr.Cover)
+			assert.Empty(t, tr.DownloadUrl)
+			assert.Zero(t, tr.ImdbRating)
+			assert.False(t, tr.Freeleech)
+			assert.False(t, tr.NoHitAndRun)
+		})
+
+	})
+
 	t.Run("paging", func(t *testing.T) {
 
 		t.Run("parse page info", func(t *testing.T) {
@@ -424,6 +519,7 @@ func TestApi_Search(t *testing.T) {
 				Current: 3,
 				Prev:    2,
 				Next:    4,
+				Last:    4,
 			}
 			results := ParseResponse(doc)
 			assert.Equal(t, expected, results.Page)
@@ -444,6 +540,7 @@ func TestApi_Search(t *testing.T) {
 				Current: 1,
 				Prev:    1,
 				Next:    2,
+				Last:    4,
 			}
 			assert.Equal(t, expected, results.Page)
 		})
@@ -462,11 +559,25 @@ func TestApi_Search(t *testing.T) {
 				Current: 4,
 				Prev:    3,
 				Next:    4,
+				Last:    4,
 			}
 			results := ParseResponse(doc)
 			assert.Equal(t, expected, results.Page)
 		})
 
+		t.Run("last page from link", func(t *testing.T) {
+			doc := parse.MustParse(t, `
+			<div id="pager_bottom">
+				<span class="active_link"><strong>1-25</strong></span>
+				| <a href="/torrents.php?oldal=2&tipus=all_own" id="nPa"><strong>26-50</strong></a>
+				| <a href="/torrents.php?oldal=3&tipus=all_own"><strong>51-75</strong></a>
+				| <a href="/torrents.php?oldal=40&tipus=all_own"><strong>Utolsó</strong></a>
+			</div>
+			`)
+			results := ParseResponse(doc)
+			assert.Equal(t, 40, results.Page.Last)
+		})
+
 		t.Run("invalid range separator", func(t *testing.T) {
 			doc := parse.MustParse(t, `
 			<div id="pager_bottom">
@@ -502,3 +613,18 @@ func TestApi_Search(t *testing.T) {
 	})
 
 }
+
+func TestMerge(t *testing.T) {
+
+	t.Run("order kept and duplicates removed", func(t *testing.T) {
+		a, b, c := &Torrent{Id: "1"}, &Torrent{Id: "2"}, &Torrent{Id: "3"}
+		merged := Merge([]*Torrent{a, b}, []*Torrent{{Id: "2"}, c}, nil)
+		assert.Equal(t, []*Torrent{a, b, c}, merged)
+	})
+
+	t.Run("torrents without id kept", func(t *testing.T) {
+		merged := Merge([]*Torrent{{}, {}})
+		assert.Len(t, merged, 2)
+	})
+
+}
@@ -0,0 +1,11 @@
+package session
+
+type Session struct {
+	Key     string    `json:"key"`
+	Cookies []*Cookie `json:"cookies"`
+}
+
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
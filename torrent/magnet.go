@@ -0,0 +1,80 @@
+package torrent
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// passkeyParams are the query parameters trackers use to identify the user
+var passkeyParams = []string{"passkey", "key", "pk", "authkey"}
+
+// passkeySegment matches a passkey used as a path segment, like in /<passkey>/announce
+var passkeySegment = regexp.MustCompile(`^[0-9a-fA-F]{32,}$`)
+
+// Trackers lists the announce urls of every tier, without duplicates.
+func (m *Metainfo) Trackers() []string {
+	var trackers []string
+	add := func(u string) {
+		if u != "" && !slices.Contains(trackers, u) {
+			trackers = append(trackers, u)
+		}
+	}
+	add(m.Announce)
+	for _, tier := range m.AnnounceList {
+		for _, u := range tier {
+			add(u)
+		}
+	}
+	return trackers
+}
+
+// Magnet builds a magnet link with the display name, exact length and trackers of the torrent.
+// The passkey identifying the user can be stripped from the tracker urls, for sharing the link.
+func (m *Metainfo) Magnet(stripPasskey bool) string {
+	sb := &strings.Builder{}
+	sb.WriteString("magnet:?xt=urn:btih:")
+	sb.WriteString(m.InfoHash)
+	if m.Name != "" {
+		sb.WriteString("&dn=")
+		sb.WriteString(url.QueryEscape(m.Name))
+	}
+	if m.Length > 0 {
+		sb.WriteString("&xl=")
+		sb.WriteString(strconv.FormatInt(m.Length, 10))
+	}
+	var trackers []string
+	for _, tr := range m.Trackers() {
+		if stripPasskey {
+			tr = StripPasskey(tr)
+		}
+		if !slices.Contains(trackers, tr) {
+			trackers = append(trackers, tr)
+		}
+	}
+	for _, tr := range trackers {
+		sb.WriteString("&tr=")
+		sb.WriteString(url.QueryEscape(tr))
+	}
+	return sb.String()
+}
+
+// StripPasskey removes the passkey from a tracker url, whether it is a query parameter or a path segment.
+func StripPasskey(tracker string) string {
+	u, err := url.Parse(tracker)
+	if err != nil {
+		return tracker
+	}
+	q := u.Query()
+	for _, p := range passkeyParams {
+		q.Del(p)
+	}
+	u.RawQuery = q.Encode()
+	segments := strings.Split(u.Path, "/")
+	segments = slices.DeleteFunc(segments, passkeySegment.MatchString)
+	u.Path = strings.Join(segments, "/")
+	u.RawPath = ""
+	return u.String()
+}
@@ -0,0 +1,64 @@
+package torrent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const passkey = "0123456789abcdef0123456789abcdef"
+
+func TestMetainfo_Trackers(t *testing.T) {
+	m := &Metainfo{
+		Announce:     "https://a/announce",
+		AnnounceList: [][]string{{"https://a/announce", "https://b/announce"}, {"udp://c"}},
+	}
+	assert.Equal(t, []string{"https://a/announce", "https://b/announce", "udp://c"}, m.Trackers())
+	assert.Nil(t, (&Metainfo{}).Trackers())
+}
+
+func TestMetainfo_Magnet(t *testing.T) {
+
+	m := &Metainfo{
+		Name:     "Some Movie (2020)",
+		Length:   1234,
+		InfoHash: "c12fe1c06bba254a9dc9f519b335aa7c1367a88a",
+		Announce: "https://t.ncore.sh:2810/" + passkey + "/announce",
+		AnnounceList: [][]string{
+			{"https://t.ncore.sh:2810/" + passkey + "/announce"},
+			{"https://t2.ncore.sh:2810/" + passkey + "/announce"},
+		},
+	}
+
+	t.Run("with passkey", func(t *testing.T) {
+		assert.Equal(t, "magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a"+
+			"&dn=Some+Movie+%282020%29&xl=1234"+
+			"&tr=https%3A%2F%2Ft.ncore.sh%3A2810%2F"+passkey+"%2Fannounce"+
+			"&tr=https%3A%2F%2Ft2.ncore.sh%3A2810%2F"+passkey+"%2Fannounce", m.Magnet(false))
+	})
+
+	t.Run("passkey stripped", func(t *testing.T) {
+		assert.Equal(t, "magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a"+
+			"&dn=Some+Movie+%282020%29&xl=1234"+
+			"&tr=https%3A%2F%2Ft.ncore.sh%3A2810%2Fannounce"+
+			"&tr=https%3A%2F%2Ft2.ncore.sh%3A2810%2Fannounce", m.Magnet(true))
+	})
+
+	t.Run("hash only", func(t *testing.T) {
+		assert.Equal(t, "magnet:?xt=urn:btih:abc", (&Metainfo{InfoHash: "abc"}).Magnet(true))
+	})
+
+}
+
+func TestStripPasskey(t *testing.T) {
+	tests := map[string]string{
+		"https://t.ncore.sh:2810/" + passkey + "/announce": "https://t.ncore.sh:2810/announce",
+		"https://tracker/announce.php?passkey=" + passkey:  "https://tracker/announce.php",
+		"https://tracker/announce?info=1&pk=" + passkey:    "https://tracker/announce?info=1",
+		"udp://tracker:80/announce":                        "udp://tracker:80/announce",
+		"https://tracker/short/announce":                   "https://tracker/short/announce",
+	}
+	for in, expected := range tests {
+		assert.Equal(t, expected, StripPasskey(in), in)
+	}
+}
@@ -0,0 +1,143 @@
+package torrent
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gar-r/ngore/bencode"
+)
+
+var ErrNotTorrent = errors.New("torrent: not a torrent file")
+var ErrMissingInfo = errors.New("torrent: missing info dictionary")
+
+// Parse decodes the contents of a .torrent file, like the one returned by Api.Download.
+func Parse(data []byte) (*Metainfo, error) {
+	v, err := bencode.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := v.(map[string]any)
+	if !ok {
+		return nil, ErrNotTorrent
+	}
+	info, ok := root["info"].(map[string]any)
+	if !ok {
+		return nil, ErrMissingInfo
+	}
+	raw, _, err := bencode.Lookup(data, "info")
+	if err != nil {
+		return nil, err
+	}
+	hash := sha1.Sum(raw)
+	m := &Metainfo{
+		Name:         utf8String(info, "name"),
+		PieceLength:  integer(info, "piece length"),
+		Announce:     str(root, "announce"),
+		AnnounceList: parseAnnounceList(root),
+		Private:      integer(info, "private") == 1,
+		Comment:      utf8String(root, "comment"),
+		CreatedBy:    str(root, "created by"),
+		CreationDate: parseDate(root),
+		InfoHash:     hex.EncodeToString(hash[:]),
+	}
+	m.Files = parseFiles(info, m.Name)
+	for _, f := range m.Files {
+		m.Length += f.Length
+	}
+	return m, nil
+}
+
+// ParseFile reads and decodes a .torrent file.
+func ParseFile(name string) (*Metainfo, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func parseFiles(info map[string]any, name string) []File {
+	list, ok := info["files"].([]any)
+	if !ok {
+		return []File{{Path: name, Length: integer(info, "length")}}
+	}
+	files := make([]File, 0, len(list))
+	for _, e := range list {
+		f, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		files = append(files, File{
+			Path:   parsePath(f),
+			Length: integer(f, "length"),
+		})
+	}
+	return files
+}
+
+func parsePath(f map[string]any) string {
+	parts, ok := f["path.utf-8"].([]any)
+	if !ok {
+		parts, _ = f["path"].([]any)
+	}
+	elems := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if s, ok := p.(string); ok {
+			elems = append(elems, s)
+		}
+	}
+	return path.Join(elems...)
+}
+
+func parseAnnounceList(root map[string]any) [][]string {
+	tiers, ok := root["announce-list"].([]any)
+	if !ok {
+		return nil
+	}
+	list := make([][]string, 0, len(tiers))
+	for _, t := range tiers {
+		urls, ok := t.([]any)
+		if !ok {
+			continue
+		}
+		tier := make([]string, 0, len(urls))
+		for _, u := range urls {
+			if s, ok := u.(string); ok {
+				tier = append(tier, s)
+			}
+		}
+		list = append(list, tier)
+	}
+	return list
+}
+
+func parseDate(root map[string]any) time.Time {
+	sec, ok := root["creation date"].(int64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
+
+// utf8String prefers the utf-8 variant of a key, which some clients add
+// next to a value written in a legacy encoding
+func utf8String(m map[string]any, key string) string {
+	if s, ok := m[key+".utf-8"].(string); ok {
+		return s
+	}
+	return str(m, key)
+}
+
+func str(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func integer(m map[string]any, key string) int64 {
+	n, _ := m[key].(int64)
+	return n
+}
@@ -0,0 +1,128 @@
+package torrent
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gar-r/ngore/bencode"
+	"github.com/stretchr/testify/assert"
+)
+
+func encode(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := bencode.Encode(v)
+	assert.NoError(t, err)
+	return b
+}
+
+func infoHash(t *testing.T, info map[string]any) string {
+	sum := sha1.Sum(encode(t, info))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParse(t *testing.T) {
+
+	t.Run("single file", func(t *testing.T) {
+		info := map[string]any{
+			"name":         "movie.mkv",
+			"length":       int64(1234),
+			"piece length": int64(16384),
+			"pieces":       "\x00\x01\x02",
+			"private":      int64(1),
+		}
+		data := encode(t, map[string]any{
+			"announce":      "https://tracker/announce",
+			"announce-list": []any{[]any{"https://tracker/announce"}, []any{"udp://backup"}},
+			"comment":       "comment",
+			"created by":    "client",
+			"creation date": int64(1700000000),
+			"info":          info,
+		})
+		m, err := Parse(data)
+		assert.NoError(t, err)
+		assert.Equal(t, &Metainfo{
+			Name:         "movie.mkv",
+			Files:        []File{{Path: "movie.mkv", Length: 1234}},
+			Length:       1234,
+			PieceLength:  16384,
+			Announce:     "https://tracker/announce",
+			AnnounceList: [][]string{{"https://tracker/announce"}, {"udp://backup"}},
+			Private:      true,
+			Comment:      "comment",
+			CreatedBy:    "client",
+			CreationDate: time.Unix(1700000000, 0).UTC(),
+			InfoHash:     infoHash(t, info),
+		}, m)
+	})
+
+	t.Run("multiple files", func(t *testing.T) {
+		data := encode(t, map[string]any{
+			"info": map[string]any{
+				"name": "season",
+				"files": []any{
+					map[string]any{"length": int64(10), "path": []any{"e01.mkv"}},
+					map[string]any{"length": int64(20), "path": []any{"subs", "e01.srt"}},
+				},
+			},
+		})
+		m, err := Parse(data)
+		assert.NoError(t, err)
+		assert.Equal(t, []File{{Path: "e01.mkv", Length: 10}, {Path: "subs/e01.srt", Length: 20}}, m.Files)
+		assert.Equal(t, int64(30), m.Length)
+		assert.False(t, m.Private)
+		assert.True(t, m.CreationDate.IsZero())
+	})
+
+	t.Run("utf-8 variants preferred", func(t *testing.T) {
+		data := encode(t, map[string]any{
+			"info": map[string]any{
+				"name":       "Arv\xe1zt\xfbr\xf5",
+				"name.utf-8": "Árvíztűrő",
+				"files": []any{
+					map[string]any{"length": int64(1), "path": []any{"a"}, "path.utf-8": []any{"á"}},
+				},
+			},
+		})
+		m, err := Parse(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "Árvíztűrő", m.Name)
+		assert.Equal(t, "á", m.Files[0].Path)
+	})
+
+	t.Run("info hash uses the original encoding", func(t *testing.T) {
+		// keys out of order, a re-encoded info dictionary would hash differently
+		data := []byte("d4:infod4:name1:a6:lengthi1eee")
+		m, err := Parse(data)
+		assert.NoError(t, err)
+		sum := sha1.Sum([]byte("d4:name1:a6:lengthi1ee"))
+		assert.Equal(t, hex.EncodeToString(sum[:]), m.InfoHash)
+	})
+
+	t.Run("not a torrent", func(t *testing.T) {
+		_, err := Parse([]byte("<html></html>"))
+		assert.Error(t, err)
+		_, err = Parse([]byte("li1ee"))
+		assert.ErrorIs(t, err, ErrNotTorrent)
+		_, err = Parse([]byte("d8:announce3:fooe"))
+		assert.ErrorIs(t, err, ErrMissingInfo)
+		_, err = Parse([]byte("d4:info3:fooe"))
+		assert.ErrorIs(t, err, ErrMissingInfo)
+	})
+
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.torrent")
+	assert.NoError(t, os.WriteFile(path, []byte("d4:infod4:name4:test6:lengthi5eee"), 0644))
+	m, err := ParseFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", m.Name)
+	assert.Equal(t, int64(5), m.Length)
+
+	_, err = ParseFile(filepath.Join(t.TempDir(), "missing.torrent"))
+	assert.Error(t, err)
+}
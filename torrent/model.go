@@ -0,0 +1,25 @@
+package torrent
+
+import "time"
+
+type Metainfo struct {
+	Name string `json:"name"`
+	// Files lists every file of the torrent. Paths are relative to Name,
+	// which is the directory of a multi-file torrent, or the file itself.
+	Files        []File     `json:"files"`
+	Length       int64      `json:"length"`
+	PieceLength  int64      `json:"pieceLength"`
+	Announce     string     `json:"announce"`
+	AnnounceList [][]string `json:"announceList,omitempty"`
+	Private      bool       `json:"private"`
+	Comment      string     `json:"comment,omitempty"`
+	CreatedBy    string     `json:"createdBy,omitempty"`
+	CreationDate time.Time  `json:"creationDate"`
+	// InfoHash is the hex encoded SHA-1 hash of the info dictionary (v1).
+	InfoHash string `json:"infoHash"`
+}
+
+type File struct {
+	Path   string `json:"path"`
+	Length int64  `json:"length"`
+}